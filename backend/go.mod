@@ -2,4 +2,4 @@ module github.com/ppodds/hide-and-seek
 
 go 1.19
 
-require google.golang.org/protobuf v1.28.1 // indirect
+require google.golang.org/protobuf v1.28.1
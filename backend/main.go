@@ -15,9 +15,20 @@ func bootstrap() *server.App {
 	app.AddTCPProc(new(tcpproc.LeaveLobby))
 	app.AddTCPProc(new(tcpproc.Logout))
 	app.AddTCPProc(new(tcpproc.StartGame))
+	app.AddTCPProc(new(tcpproc.Heartbeat))
+	app.AddTCPProc(new(tcpproc.Resume))
+	app.AddTCPProc(new(tcpproc.TransferLead))
+	app.AddTCPProc(new(tcpproc.KickPlayer))
+	app.AddTCPProc(new(tcpproc.BanPlayer))
+	app.AddTCPProc(new(tcpproc.SetReady))
+	app.AddTCPProc(new(tcpproc.SendChat))
+	app.AddTCPProc(new(tcpproc.JoinQueue))
+	app.AddTCPProc(new(tcpproc.LeaveQueue))
 	app.AddUDPProc(new(udpproc.ConnectLobby))
 	app.AddUDPProc(new(udpproc.ConnectGame))
 	app.AddUDPProc(new(udpproc.UpdatePlayer))
+	app.AddUDPProc(new(udpproc.AckReliable))
+	app.AddUDPProc(new(udpproc.Heartbeat))
 	return app
 }
 
@@ -0,0 +1,128 @@
+package account
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	MaxUsernameLength = 16
+	saltSize          = 16
+	// pbkdf2Iterations follow the OWASP recommendation for PBKDF2-HMAC-SHA256.
+	pbkdf2Iterations = 600000
+	pbkdf2KeySize    = sha256.Size
+)
+
+var (
+	ErrNotFound = errors.New("account not found")
+	ErrExists   = errors.New("account already exists")
+)
+
+type Stats struct {
+	Games   uint32 `json:"games"`
+	Wins    uint32 `json:"wins"`
+	Losses  uint32 `json:"losses"`
+	Catches uint32 `json:"catches"`
+}
+
+type Account struct {
+	Username     string    `json:"username"`
+	PasswordHash string    `json:"passwordHash,omitempty"`
+	Salt         string    `json:"salt,omitempty"`
+	Stats        Stats     `json:"stats"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// Store keep accounts across server restarts.
+type Store interface {
+	// Get return the account of username. Return ErrNotFound if there is no such account.
+	Get(username string) (*Account, error)
+	// Create save a new account. Return ErrExists if there is already an account of the username.
+	Create(account *Account) error
+	// Save create or overwrite the account.
+	Save(account *Account) error
+}
+
+func NewAccount(username string) *Account {
+	account := new(Account)
+	account.Username = username
+	account.CreatedAt = time.Now()
+	return account
+}
+
+// NormalizeUsername trim the username and check if it is valid.
+func NormalizeUsername(username string) (string, error) {
+	username = strings.TrimSpace(username)
+	if username == "" {
+		return "", errors.New("username is empty")
+	}
+	if utf8.RuneCountInString(username) > MaxUsernameLength {
+		return "", errors.New("username is too long")
+	}
+	return username, nil
+}
+
+func (account *Account) HasPassword() bool {
+	return account.PasswordHash != ""
+}
+
+func (account *Account) SetPassword(password string) error {
+	salt := make([]byte, saltSize)
+	_, err := rand.Read(salt)
+	if err != nil {
+		return err
+	}
+	account.Salt = hex.EncodeToString(salt)
+	account.PasswordHash = hashPassword(account.Salt, password)
+	return nil
+}
+
+// CheckPassword report whether password is the password of the account. An account without password accept any
+// password.
+func (account *Account) CheckPassword(password string) bool {
+	if !account.HasPassword() {
+		return true
+	}
+	hash := hashPassword(account.Salt, password)
+	return subtle.ConstantTimeCompare([]byte(hash), []byte(account.PasswordHash)) == 1
+}
+
+func hashPassword(salt string, password string) string {
+	return hex.EncodeToString(pbkdf2([]byte(password), []byte(salt), pbkdf2Iterations, pbkdf2KeySize))
+}
+
+// pbkdf2 derive a key of keySize bytes with PBKDF2-HMAC-SHA256. It is implemented here because the module doesn't
+// depend on golang.org/x/crypto and crypto/pbkdf2 needs a newer go version.
+func pbkdf2(password []byte, salt []byte, iterations int, keySize int) []byte {
+	prf := hmac.New(sha256.New, password)
+	key := make([]byte, 0, keySize)
+	u := make([]byte, 0, sha256.Size)
+	t := make([]byte, sha256.Size)
+	index := make([]byte, 4)
+	for block := uint32(1); len(key) < keySize; block++ {
+		binary.BigEndian.PutUint32(index, block)
+		prf.Reset()
+		prf.Write(salt)
+		prf.Write(index)
+		u = prf.Sum(u[:0])
+		copy(t, u)
+		for i := 1; i < iterations; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+		key = append(key, t...)
+	}
+	return key[:keySize]
+}
@@ -0,0 +1,58 @@
+package account
+
+import (
+	"encoding/hex"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestPBKDF2(t *testing.T) {
+	// the first vectors are from RFC 7914 section 11, the last one is computed with python hashlib.pbkdf2_hmac
+	tests := []struct {
+		password   string
+		salt       string
+		iterations int
+		keySize    int
+		want       string
+	}{
+		{"passwd", "salt", 1, 64, "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783"},
+		{"Password", "NaCl", 80000, 64, "4ddcd8f60b98be21830cee5ef22701f9641a4418d04c0414aeff08876b34ab56a1d425a1225833549adb841b51c9b3176a272bdebba1d078478f62b397f33c8d"},
+		{"password", "salt", 2, 32, "ae4d0c95af6b46d32d0adff928f06dd02a303f8ef3c251dfd6e2d85a95474c43"},
+	}
+	for _, tt := range tests {
+		got := hex.EncodeToString(pbkdf2([]byte(tt.password), []byte(tt.salt), tt.iterations, tt.keySize))
+		if got != tt.want {
+			t.Errorf("pbkdf2(%q, %q, %d) = %s, want %s", tt.password, tt.salt, tt.iterations, got, tt.want)
+		}
+	}
+}
+
+func TestCheckPassword(t *testing.T) {
+	acc := NewAccount("alice")
+	if err := acc.SetPassword("secret"); err != nil {
+		t.Fatal(err)
+	}
+	if !acc.CheckPassword("secret") {
+		t.Fatal("right password rejected")
+	}
+	if acc.CheckPassword("wrong") {
+		t.Fatal("wrong password accepted")
+	}
+}
+
+func TestFileStoreCreate(t *testing.T) {
+	store, err := NewFileStore(filepath.Join(t.TempDir(), "accounts.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = store.Create(NewAccount("alice")); err != nil {
+		t.Fatal(err)
+	}
+	if err = store.Create(NewAccount("alice")); !errors.Is(err, ErrExists) {
+		t.Fatalf("second create error = %v, want %v", err, ErrExists)
+	}
+	if _, err = store.Get("alice"); err != nil {
+		t.Fatal(err)
+	}
+}
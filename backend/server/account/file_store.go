@@ -0,0 +1,103 @@
+package account
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// FileStore keep every account in a json file. The whole file is rewritten on each save.
+type FileStore struct {
+	path     string
+	accounts map[string]*Account
+	sync.Mutex
+}
+
+// NewFileStore load the accounts from path. The file is created on the first save if it doesn't exist.
+func NewFileStore(path string) (*FileStore, error) {
+	store := new(FileStore)
+	store.path = path
+	store.accounts = make(map[string]*Account)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	accounts := make([]*Account, 0)
+	err = json.Unmarshal(data, &accounts)
+	if err != nil {
+		return nil, err
+	}
+	for _, account := range accounts {
+		store.accounts[account.Username] = account
+	}
+	return store, nil
+}
+
+func (store *FileStore) Get(username string) (*Account, error) {
+	store.Lock()
+	defer store.Unlock()
+	account, ok := store.accounts[username]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	// return a copy, so changes are only kept after Save
+	copied := *account
+	return &copied, nil
+}
+
+func (store *FileStore) Create(account *Account) error {
+	store.Lock()
+	defer store.Unlock()
+	if _, ok := store.accounts[account.Username]; ok {
+		return ErrExists
+	}
+	copied := *account
+	store.accounts[account.Username] = &copied
+	err := store.flush()
+	if err != nil {
+		delete(store.accounts, account.Username)
+		return err
+	}
+	return nil
+}
+
+func (store *FileStore) Save(account *Account) error {
+	store.Lock()
+	defer store.Unlock()
+	copied := *account
+	store.accounts[account.Username] = &copied
+	return store.flush()
+}
+
+// flush write all accounts to a temporary file and rename it, so the file is never left half written.
+func (store *FileStore) flush() error {
+	accounts := make([]*Account, 0, len(store.accounts))
+	for _, account := range store.accounts {
+		accounts = append(accounts, account)
+	}
+	data, err := json.MarshalIndent(accounts, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(store.path), filepath.Base(store.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	err = tmp.Close()
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), store.path)
+}
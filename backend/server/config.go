@@ -0,0 +1,99 @@
+package server
+
+import (
+	"flag"
+	"time"
+
+	"github.com/ppodds/hide-and-seek/server/rpc"
+)
+
+type Config struct {
+	Host              string
+	ProcPort          string
+	GamePort          string
+	MaxFrameSize      uint
+	IOTimeout         time.Duration
+	IdleTimeout       time.Duration
+	TickRate          uint
+	CatchRadius       float64
+	GhostMaxSpeed     float64
+	PlayerMaxSpeed    float64
+	KickViolations    uint
+	AccountsPath      string
+	HeartbeatTimeout  time.Duration
+	GracePeriod       time.Duration
+	MaxLobbyPlayers   uint
+	MaxRoundDuration  time.Duration
+	MaxCatchRadius    float64
+	MaxChatLength     uint
+	ChatInterval      time.Duration
+	ChatBurst         uint
+	ChatHistory       uint
+	MatchTimeout      time.Duration
+	MapsPath          string
+	MaxGhosts         uint
+	MaxRounds         uint
+	RoundIntermission time.Duration
+}
+
+func DefaultConfig() *Config {
+	return &Config{
+		Host:              "localhost",
+		ProcPort:          "23455",
+		GamePort:          "23456",
+		MaxFrameSize:      rpc.DefaultMaxFrameSize,
+		IOTimeout:         10 * time.Second,
+		IdleTimeout:       0,
+		TickRate:          20,
+		CatchRadius:       1.5,
+		GhostMaxSpeed:     12,
+		PlayerMaxSpeed:    10,
+		KickViolations:    0,
+		AccountsPath:      "accounts.json",
+		HeartbeatTimeout:  15 * time.Second,
+		GracePeriod:       30 * time.Second,
+		MaxLobbyPlayers:   8,
+		MaxRoundDuration:  10 * time.Minute,
+		MaxCatchRadius:    5,
+		MaxChatLength:     200,
+		ChatInterval:      time.Second,
+		ChatBurst:         5,
+		ChatHistory:       50,
+		MatchTimeout:      30 * time.Second,
+		MapsPath:          "maps",
+		MaxGhosts:         3,
+		MaxRounds:         10,
+		RoundIntermission: 10 * time.Second,
+	}
+}
+
+// ParseFlags overwrite the config with command line flags. Current values are used as defaults.
+func (config *Config) ParseFlags() {
+	flag.StringVar(&config.Host, "host", config.Host, "host")
+	flag.StringVar(&config.ProcPort, "tcpproc-port", config.ProcPort, "procedure port")
+	flag.StringVar(&config.GamePort, "game-port", config.GamePort, "game port")
+	flag.UintVar(&config.MaxFrameSize, "max-frame-size", config.MaxFrameSize, "max size of a tcp frame in bytes")
+	flag.DurationVar(&config.IOTimeout, "io-timeout", config.IOTimeout, "read/write deadline of a tcp frame")
+	flag.DurationVar(&config.IdleTimeout, "idle-timeout", config.IdleTimeout, "close a tcp session without calls for this long, 0 to disable")
+	flag.UintVar(&config.TickRate, "tick-rate", config.TickRate, "game simulation ticks per second")
+	flag.Float64Var(&config.CatchRadius, "catch-radius", config.CatchRadius, "max distance for the ghost to catch a player")
+	flag.Float64Var(&config.GhostMaxSpeed, "ghost-max-speed", config.GhostMaxSpeed, "max moving speed of the ghost")
+	flag.Float64Var(&config.PlayerMaxSpeed, "player-max-speed", config.PlayerMaxSpeed, "max moving speed of a player")
+	flag.UintVar(&config.KickViolations, "kick-violations", config.KickViolations, "kick a player after this many illegal moves, 0 to disable")
+	flag.StringVar(&config.AccountsPath, "accounts", config.AccountsPath, "path of the account file")
+	flag.DurationVar(&config.HeartbeatTimeout, "heartbeat-timeout", config.HeartbeatTimeout, "disconnect a player without any call for this long")
+	flag.DurationVar(&config.GracePeriod, "grace-period", config.GracePeriod, "keep a disconnected player in its game for this long to resume, 0 to disable")
+	flag.UintVar(&config.MaxLobbyPlayers, "max-lobby-players", config.MaxLobbyPlayers, "max players of a lobby")
+	flag.DurationVar(&config.MaxRoundDuration, "max-round-duration", config.MaxRoundDuration, "max round duration of a lobby")
+	flag.Float64Var(&config.MaxCatchRadius, "max-catch-radius", config.MaxCatchRadius, "max catch radius of a lobby")
+	flag.UintVar(&config.MaxChatLength, "max-chat-length", config.MaxChatLength, "max characters of a chat message")
+	flag.DurationVar(&config.ChatInterval, "chat-interval", config.ChatInterval, "average time between chat messages of a player")
+	flag.UintVar(&config.ChatBurst, "chat-burst", config.ChatBurst, "chat messages a player can send at once")
+	flag.UintVar(&config.ChatHistory, "chat-history", config.ChatHistory, "chat messages kept for players who join a lobby")
+	flag.DurationVar(&config.MatchTimeout, "match-timeout", config.MatchTimeout, "form a lobby which is not full after a player waited this long in the matchmaking queue")
+	flag.StringVar(&config.MapsPath, "maps", config.MapsPath, "directory of the map files")
+	flag.UintVar(&config.MaxGhosts, "max-ghosts", config.MaxGhosts, "max ghosts of a lobby")
+	flag.UintVar(&config.MaxRounds, "max-rounds", config.MaxRounds, "max rounds of a match")
+	flag.DurationVar(&config.RoundIntermission, "round-intermission", config.RoundIntermission, "time between the rounds of a match")
+	flag.Parse()
+}
@@ -0,0 +1,143 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/ppodds/hide-and-seek/protos"
+	"github.com/ppodds/hide-and-seek/server/lobby"
+	"github.com/ppodds/hide-and-seek/server/player"
+)
+
+// LeaveLobby remove the player from the lobby and notify the other members. The lobby is destroyed if nobody is left,
+// or if the lead leave and the lobby doesn't hand the lead over.
+func (app *App) LeaveLobby(l *lobby.Lobby, p *player.Player) error {
+	l, err := l.RmPeople(p)
+	if err != nil {
+		return err
+	}
+	leadLeft := l.Lead().ID == p.ID
+	if l.CurPeople() == 0 || (leadLeft && !l.Settings().LeadHandover) {
+		app.Lobbies.RmLobby(l.ID)
+		return Push(l.Players(), &protos.LobbyBroadcast{Event: protos.LobbyEvent_DESTROY})
+	}
+	event := protos.LobbyEvent_LEAVE
+	if leadLeft {
+		// players are kept in join order, so the first one has been in the lobby the longest
+		err = l.SetLead(l.Players()[0])
+		if err != nil {
+			return err
+		}
+		event = protos.LobbyEvent_LEAD_CHANGED
+	}
+	lobbyProto, err := l.MarshalProtoBuf()
+	if err != nil {
+		return err
+	}
+	return Push(l.Players(), &protos.LobbyBroadcast{Event: event, Lobby: lobbyProto})
+}
+
+// Kick remove the player from the lobby. The player is told with KICKED and the other members with LEAVE.
+func (app *App) Kick(l *lobby.Lobby, p *player.Player) error {
+	l, err := l.RmPeople(p)
+	if err != nil {
+		return err
+	}
+	lobbyProto, err := l.MarshalProtoBuf()
+	if err != nil {
+		return err
+	}
+	err = Push([]*player.Player{p}, &protos.LobbyBroadcast{Event: protos.LobbyEvent_KICKED, Lobby: lobbyProto})
+	if err != nil {
+		fmt.Println("failed to notify kicked player", p.ID, "because", err)
+	}
+	return Push(l.Players(), &protos.LobbyBroadcast{Event: protos.LobbyEvent_LEAVE, Lobby: lobbyProto})
+}
+
+// Disconnect log out the player. The player leave the lobby and the game it is in, and its resources are freed.
+// The tcp session is kept, so the client can log in again.
+func (app *App) Disconnect(p *player.Player) {
+	app.Players.RmPlayer(p.ID)
+	app.Matchmaker.Dequeue(p.ID)
+	for _, l := range app.Lobbies.Lobbies() {
+		if !l.HasPlayer(p.ID) {
+			continue
+		}
+		err := app.LeaveLobby(l, p)
+		if err != nil {
+			fmt.Println("failed to remove player", p.ID, "from lobby", l.ID, "because", err)
+		}
+	}
+	for _, g := range app.Games.Games() {
+		g.Leave(p.ID)
+	}
+	if addr := p.UDPAddr(); addr != nil {
+		app.Reliable.RmPeer(addr)
+	}
+}
+
+// dropUnreachable close the session of the player bound to addr, because it doesn't ack reliable datagrams. The client
+// has to resume and handshake again, which restart its reliable sequence.
+func (app *App) dropUnreachable(addr *net.UDPAddr) {
+	for _, p := range app.Players.Players() {
+		if p.UDPAddr() == nil || p.UDPAddr().String() != addr.String() {
+			continue
+		}
+		fmt.Println("player", p.ID, "doesn't ack reliable udp responses")
+		session := p.Session()
+		if session == nil {
+			continue
+		}
+		err := session.Close()
+		if err != nil {
+			fmt.Println("Error closing session:", err)
+		}
+	}
+}
+
+// watchPlayers disconnect players who send nothing within the heartbeat timeout and detached players whose grace
+// period is over. Players in a game must also keep sending udp calls. It blocks, so it should be called in a new
+// goroutine.
+func (app *App) watchPlayers() {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		inGame := make(map[uint32]time.Time)
+		for _, g := range app.Games.Games() {
+			for id := range g.Players() {
+				inGame[id] = g.StartFrom()
+			}
+		}
+		for _, p := range app.Players.Players() {
+			session := p.Session()
+			if session == nil {
+				if now.Sub(p.DetachedAt()) > app.Config.GracePeriod {
+					fmt.Println("grace period of player", p.ID, "is over")
+					app.Disconnect(p)
+				}
+				continue
+			}
+			if app.Config.HeartbeatTimeout == 0 {
+				continue
+			}
+			dead := now.Sub(session.LastSeen()) > app.Config.HeartbeatTimeout
+			if startFrom, ok := inGame[p.ID]; ok {
+				udpSeen := p.UDPSeen()
+				if udpSeen.Before(startFrom) {
+					udpSeen = startFrom
+				}
+				dead = dead || now.Sub(udpSeen) > app.Config.HeartbeatTimeout
+			}
+			if !dead {
+				continue
+			}
+			fmt.Println("player", p.ID, "timed out")
+			// closing the session end the session loop, which disconnect or detach the player
+			err := session.Close()
+			if err != nil {
+				fmt.Println("Error closing session:", err)
+			}
+		}
+	}
+}
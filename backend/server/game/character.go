@@ -3,6 +3,7 @@ package game
 import (
 	"github.com/ppodds/hide-and-seek/protos"
 	"sync"
+	"time"
 )
 
 type CharacterType int
@@ -12,32 +13,47 @@ const (
 	PLAYER
 )
 
+func (charType CharacterType) MarshalProtoBuf() protos.CharacterType {
+	if charType == GHOST {
+		return protos.CharacterType_GHOST
+	}
+	return protos.CharacterType_PLAYER
+}
+
 type Character struct {
-	charType CharacterType
-	dead     bool
-	pos      *Vector3
-	rotation *Vector3
-	velocity *Vector3
+	charType  CharacterType
+	dead      bool
+	pos       *Vector3
+	rotation  *Vector3
+	velocity  *Vector3
+	updatedAt time.Time
 	sync.RWMutex
 }
 
 func NewCharacter() *Character {
 	character := new(Character)
 	character.charType = PLAYER
-	character.pos = &Vector3{X: 78.68, Y: 23.71, Z: 44.98}
+	// the position is set by the map when the game is created
+	character.pos = new(Vector3)
 	character.rotation = new(Vector3)
 	character.velocity = new(Vector3)
+	character.updatedAt = time.Now()
 	return character
 }
 
+// FromProtobuf update the character with the state sent by the client. Whether the character is dead is decided by the
+// server, so the dead flag is ignored.
 func (character *Character) FromProtobuf(v *protos.Character) {
-	character.dead = v.Dead
+	character.Lock()
+	defer character.Unlock()
 	character.pos = ProtobufToVector3(v.Pos)
 	character.rotation = ProtobufToVector3(v.Rotation)
 	character.velocity = ProtobufToVector3(v.Velocity)
 }
 
 func (character *Character) MarshalProtoBuf() (*protos.Character, error) {
+	character.RLock()
+	defer character.RUnlock()
 	pos, err := character.pos.MarshalProtoBuf()
 	if err != nil {
 		return nil, err
@@ -50,12 +66,8 @@ func (character *Character) MarshalProtoBuf() (*protos.Character, error) {
 	if err3 != nil {
 		return nil, err3
 	}
-	charType := protos.CharacterType_PLAYER
-	if character.charType == GHOST {
-		charType = protos.CharacterType_GHOST
-	}
 	return &protos.Character{
-		Type:     charType,
+		Type:     character.charType.MarshalProtoBuf(),
 		Dead:     character.dead,
 		Pos:      pos,
 		Rotation: rotation,
@@ -73,4 +85,31 @@ func (character *Character) SetPos(v *Vector3) {
 	character.Lock()
 	defer character.Unlock()
 	character.pos = v
+	character.updatedAt = time.Now()
+}
+
+func (character *Character) SetDead(v bool) {
+	character.Lock()
+	defer character.Unlock()
+	character.dead = v
+}
+
+func (character *Character) Pos() *Vector3 {
+	character.RLock()
+	defer character.RUnlock()
+	return character.pos
+}
+
+// SetType change the character type. Modes should use Game.SetRole once the game is running, so the ghosts of the game
+// and the clients are updated.
+func (character *Character) SetType(v CharacterType) {
+	character.Lock()
+	defer character.Unlock()
+	character.charType = v
+}
+
+func (character *Character) Type() CharacterType {
+	character.RLock()
+	defer character.RUnlock()
+	return character.charType
 }
@@ -0,0 +1,110 @@
+package game
+
+import (
+	"github.com/ppodds/hide-and-seek/protos"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+const (
+	// catchPoints is the points of a ghost for each catch.
+	catchPoints = 100
+	// survivePoints is the points of a player for each second it is not caught.
+	survivePoints = 1
+)
+
+func init() {
+	RegisterMode(ClassicMode, new(Classic))
+}
+
+// Classic is the original mode. Caught players are dead, and the ghosts need to catch every player before the round
+// is over. Other modes can embed it to keep its rules.
+type Classic struct {
+}
+
+// AssignRoles pick the ghosts in the order of settings.GhostOrder, then randomly, keeping at least one player who is not
+// a ghost.
+func (classic *Classic) AssignRoles(players []*Player, settings Settings, r *rand.Rand) {
+	ghosts := int(settings.Ghosts)
+	if ghosts > len(players)-1 {
+		ghosts = len(players) - 1
+	}
+	if ghosts < 1 {
+		ghosts = 1
+	}
+	priority := make(map[uint32]int, len(settings.GhostOrder))
+	for i, id := range settings.GhostOrder {
+		priority[id] = i
+	}
+	rank := func(p *Player) int {
+		if i, ok := priority[p.Player().ID]; ok {
+			return i
+		}
+		return len(settings.GhostOrder)
+	}
+	order := r.Perm(len(players))
+	sort.SliceStable(order, func(i, j int) bool {
+		return rank(players[order[i]]) < rank(players[order[j]])
+	})
+	for i, picked := range order {
+		charType := PLAYER
+		if i < ghosts {
+			charType = GHOST
+		}
+		players[picked].Character().SetType(charType)
+	}
+}
+
+func (classic *Classic) Spawn(players []*Player, m *Map, r *rand.Rand) {
+	m.Spread(players, r)
+}
+
+func (classic *Classic) OnTick(game *Game, now time.Time) {
+}
+
+func (classic *Classic) OnCatch(game *Game, caught *Player, catcher *Player) {
+	caught.Character().SetDead(true)
+	game.Catch(caught, catcher)
+}
+
+// CheckEnd end the game when every ghost left, no player is alive, or the round is over. The players win unless no
+// player is alive.
+func (classic *Classic) CheckEnd(game *Game) (protos.CharacterType, bool) {
+	ghosts := game.Ghosts()
+	if len(ghosts) == 0 {
+		return protos.CharacterType_PLAYER, true
+	}
+	liveCount := 0
+	for id, p := range game.Players() {
+		if _, ok := ghosts[id]; !ok && !p.Character().Dead() {
+			liveCount++
+		}
+	}
+	if liveCount == 0 {
+		return protos.CharacterType_GHOST, true
+	}
+	if time.Since(game.StartFrom()) > game.Settings().RoundDuration {
+		return protos.CharacterType_PLAYER, true
+	}
+	return protos.CharacterType_PLAYER, false
+}
+
+// Score give points for each catch, and for each second a player survived until it is caught or the game is over.
+func (classic *Classic) Score(game *Game) map[uint32]uint32 {
+	now := time.Now()
+	startFrom := game.StartFrom()
+	scores := make(map[uint32]uint32)
+	for id, p := range game.Players() {
+		scores[id] = p.Catches() * catchPoints
+		end := p.CaughtAt()
+		if end.IsZero() {
+			if p.Character().Type() != PLAYER {
+				continue
+			}
+			end = now
+		}
+		scores[id] += uint32(end.Sub(startFrom)/time.Second) * survivePoints
+	}
+	return scores
+}
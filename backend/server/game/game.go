@@ -1,22 +1,73 @@
 package game
 
-import "time"
+import (
+	"errors"
+	"fmt"
+	"github.com/ppodds/hide-and-seek/protos"
+	"github.com/ppodds/hide-and-seek/server/rpc"
+	"sync"
+	"time"
+)
+
+// DefaultMap is the map of a lobby when the lead doesn't choose one.
+const DefaultMap = "default"
+
+type Settings struct {
+	// TickRate is the number of simulation ticks per second.
+	TickRate uint
+	// CatchRadius is the max distance between a ghost and a player to catch the player.
+	CatchRadius float32
+	// Ghosts is the number of ghosts. At least one player is not a ghost.
+	Ghosts uint32
+	// GhostOrder is the ids of the players who should be the ghosts first. It is up to the mode to follow it.
+	GhostOrder []uint32
+	// RoundDuration is the time the players need to survive to win.
+	RoundDuration time.Duration
+	Map           *Map
+	// MaxSpeed is the max moving speed of each character type in units per second.
+	MaxSpeed map[CharacterType]float32
+	// Mode is the rule set of the game. The classic mode is used if it is nil.
+	Mode GameMode
+	// KickViolations is the number of illegal moves before a player is kicked. Zero means never kick.
+	KickViolations uint
+}
 
 type Game struct {
 	id        uint32
 	lobbyID   uint32
 	players   map[uint32]*Player
-	ghost     *Player
+	ghosts    map[uint32]*Player
+	settings  Settings
 	startFrom time.Time
+	tick      uint32
+	winner    protos.CharacterType
+	scores    map[uint32]uint32
+	inputs    map[uint32]*protos.Character
+	acks      map[uint32]uint32
+	inputLock sync.Mutex
+	history   map[uint32]map[uint32]characterState
+	reliable  *rpc.ReliableUDP
+	sync.RWMutex
 }
 
-func NewGame(id uint32, lobbyID uint32, players map[uint32]*Player, ghost *Player) *Game {
+// NewGame create a game of the players. Players whose character is a ghost are the ghosts of the game.
+func NewGame(id uint32, lobbyID uint32, players map[uint32]*Player, settings Settings, reliable *rpc.ReliableUDP) *Game {
 	game := new(Game)
 	game.id = id
 	game.lobbyID = lobbyID
 	game.players = players
-	game.ghost = ghost
+	game.ghosts = make(map[uint32]*Player)
+	for id, p := range players {
+		if p.Character().Type() == GHOST {
+			game.ghosts[id] = p
+		}
+	}
+	game.settings = settings
+	game.reliable = reliable
 	game.startFrom = time.Now()
+	game.inputs = make(map[uint32]*protos.Character)
+	game.acks = make(map[uint32]uint32)
+	game.history = make(map[uint32]map[uint32]characterState)
 	return game
 }
 
@@ -24,18 +75,179 @@ func (game *Game) ID() uint32 {
 	return game.id
 }
 
+// Players return a copy of the players in the game, so it is safe to iterate while players leave.
 func (game *Game) Players() map[uint32]*Player {
-	return game.players
+	game.RLock()
+	defer game.RUnlock()
+	players := make(map[uint32]*Player, len(game.players))
+	for id, p := range game.players {
+		players[id] = p
+	}
+	return players
+}
+
+// Ghosts return a copy of the ghosts still in the game.
+func (game *Game) Ghosts() map[uint32]*Player {
+	game.RLock()
+	defer game.RUnlock()
+	ghosts := make(map[uint32]*Player, len(game.ghosts))
+	for id, p := range game.ghosts {
+		ghosts[id] = p
+	}
+	return ghosts
+}
+
+// Leave remove the player from the game and notify the other players.
+func (game *Game) Leave(id uint32) bool {
+	p, ok := game.Players()[id]
+	if !ok {
+		return false
+	}
+	if !game.RmPlayer(id) {
+		return false
+	}
+	data, err := p.MarshalProtoBuf()
+	if err != nil {
+		fmt.Println("failed to marshal player", id, "who left because", err)
+		return true
+	}
+	game.broadcast(&protos.GameBroadcast{
+		Event:  protos.GameEvent_PLAYER_LEFT,
+		Player: data,
+	})
+	return true
 }
 
-func (game *Game) Ghost() *Player {
-	return game.ghost
+// SetRole change the character type of the player during the game and notify every player.
+func (game *Game) SetRole(p *Player, charType CharacterType) {
+	p.Character().SetType(charType)
+	game.Lock()
+	if charType == GHOST {
+		game.ghosts[p.Player().ID] = p
+	} else {
+		delete(game.ghosts, p.Player().ID)
+	}
+	game.Unlock()
+	data, err := p.MarshalProtoBuf()
+	if err != nil {
+		fmt.Println("failed to marshal player", p.Player().ID, "because", err)
+		return
+	}
+	game.broadcast(&protos.GameBroadcast{
+		Event:  protos.GameEvent_ROLE_CHANGED,
+		Player: data,
+	})
 }
 
+// RmPlayer remove the player from game. Return true if success, else false.
+func (game *Game) RmPlayer(id uint32) bool {
+	game.Lock()
+	defer game.Unlock()
+	_, ok := game.players[id]
+	if !ok {
+		return false
+	}
+	delete(game.players, id)
+	delete(game.ghosts, id)
+	return true
+}
+
+// StartFrom return the time the game started running.
 func (game *Game) StartFrom() time.Time {
+	game.RLock()
+	defer game.RUnlock()
 	return game.startFrom
 }
 
 func (game *Game) LobbyID() uint32 {
 	return game.lobbyID
 }
+
+func (game *Game) Settings() Settings {
+	return game.settings
+}
+
+// MarshalProtoBuf return the current state of the game, which is enough for a client to join the game.
+func (game *Game) MarshalProtoBuf() (*protos.InitGame, error) {
+	players := make(map[uint32]*protos.GamePlayer)
+	for id, p := range game.Players() {
+		data, err := p.MarshalProtoBuf()
+		if err != nil {
+			return nil, err
+		}
+		players[id] = data
+	}
+	ghosts := make([]uint32, 0)
+	for id := range game.Ghosts() {
+		ghosts = append(ghosts, id)
+	}
+	game.RLock()
+	defer game.RUnlock()
+	return &protos.InitGame{
+		Game:    &protos.Game{Id: game.id},
+		Players: players,
+		Elapsed: uint64(time.Since(game.startFrom).Milliseconds()),
+		Ghosts:  ghosts,
+		Tick:    game.tick,
+	}, nil
+}
+
+// Scores return a copy of the points of every player given by the mode. It is only meaningful after the game is over.
+func (game *Game) Scores() map[uint32]uint32 {
+	game.RLock()
+	defer game.RUnlock()
+	scores := make(map[uint32]uint32, len(game.scores))
+	for id, score := range game.scores {
+		scores[id] = score
+	}
+	return scores
+}
+
+// Winner return the side which won the game. It is only meaningful after the game is over.
+func (game *Game) Winner() protos.CharacterType {
+	return game.winner
+}
+
+// Input queue the character state sent by a player. Only the latest input of each player is applied on the next tick.
+func (game *Game) Input(playerID uint32, character *protos.Character) error {
+	if _, ok := game.Players()[playerID]; !ok {
+		return errors.New("player is not in the game")
+	}
+	if character == nil || character.Pos == nil || character.Rotation == nil || character.Velocity == nil {
+		return errors.New("incomplete character state")
+	}
+	game.inputLock.Lock()
+	defer game.inputLock.Unlock()
+	game.inputs[playerID] = character
+	return nil
+}
+
+// Ack record the latest snapshot received by the player, which is used as the baseline of the next snapshot.
+// Stale acks from reordered datagrams and acks of ticks which are not produced yet are ignored.
+func (game *Game) Ack(playerID uint32, tick uint32) {
+	game.RLock()
+	cur := game.tick
+	game.RUnlock()
+	if tick > cur {
+		return
+	}
+	game.inputLock.Lock()
+	defer game.inputLock.Unlock()
+	if tick > game.acks[playerID] {
+		game.acks[playerID] = tick
+	}
+}
+
+func (game *Game) ackOf(playerID uint32) uint32 {
+	game.inputLock.Lock()
+	defer game.inputLock.Unlock()
+	return game.acks[playerID]
+}
+
+func (game *Game) drainInputs() map[uint32]*protos.Character {
+	game.inputLock.Lock()
+	defer game.inputLock.Unlock()
+	inputs := game.inputs
+	game.inputs = make(map[uint32]*protos.Character)
+	return inputs
+}
@@ -0,0 +1,160 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+)
+
+// Box is an axis-aligned box.
+type Box struct {
+	Min Vector3 `json:"min"`
+	Max Vector3 `json:"max"`
+}
+
+func (box *Box) Contains(v *Vector3) bool {
+	return v.X >= box.Min.X && v.X <= box.Max.X &&
+		v.Y >= box.Min.Y && v.Y <= box.Max.Y &&
+		v.Z >= box.Min.Z && v.Z <= box.Max.Z
+}
+
+// Map describe where characters spawn and where they can move.
+type Map struct {
+	Name   string
+	Spawns map[CharacterType][]*Vector3
+	// Bounds is the playable area. Characters can't leave it.
+	Bounds Box
+	// Blocked are regions inside the bounds which characters can't enter.
+	Blocked []Box
+}
+
+// mapFile is the json format of a map.
+type mapFile struct {
+	Name   string `json:"name"`
+	Spawns struct {
+		Ghost  []*Vector3 `json:"ghost"`
+		Player []*Vector3 `json:"player"`
+	} `json:"spawns"`
+	Bounds  Box   `json:"bounds"`
+	Blocked []Box `json:"blocked"`
+}
+
+// LoadMap read a map from a json file and check that its spawn points are usable. Errors are prefixed with the path.
+func LoadMap(path string) (*Map, error) {
+	m, err := loadMap(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return m, nil
+}
+
+func loadMap(path string) (*Map, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file mapFile
+	err = json.Unmarshal(data, &file)
+	if err != nil {
+		return nil, err
+	}
+	if file.Name == "" {
+		return nil, errors.New("map has no name")
+	}
+	m := &Map{
+		Name: file.Name,
+		Spawns: map[CharacterType][]*Vector3{
+			GHOST:  file.Spawns.Ghost,
+			PLAYER: file.Spawns.Player,
+		},
+		Bounds:  file.Bounds,
+		Blocked: file.Blocked,
+	}
+	for charType, spawns := range m.Spawns {
+		if len(spawns) == 0 {
+			return nil, fmt.Errorf("map %s has no spawn point for character type %d", m.Name, charType)
+		}
+		for i, spawn := range spawns {
+			if spawn == nil {
+				return nil, fmt.Errorf("spawn point %d of character type %d of map %s is null", i, charType, m.Name)
+			}
+			if !m.Allowed(spawn) {
+				return nil, fmt.Errorf("spawn point %v of map %s is not allowed", *spawn, m.Name)
+			}
+		}
+	}
+	return m, nil
+}
+
+// LoadMaps read every json file in dir. Return the maps by name.
+func LoadMaps(dir string) (map[string]*Map, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	maps := make(map[string]*Map)
+	for _, path := range paths {
+		m, err := LoadMap(path)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := maps[m.Name]; ok {
+			return nil, fmt.Errorf("%s: map %s is defined twice", path, m.Name)
+		}
+		maps[m.Name] = m
+	}
+	return maps, nil
+}
+
+// Allowed report whether a character can be at pos.
+func (m *Map) Allowed(pos *Vector3) bool {
+	if !m.Bounds.Contains(pos) {
+		return false
+	}
+	for _, box := range m.Blocked {
+		if box.Contains(pos) {
+			return false
+		}
+	}
+	return true
+}
+
+// Spread place every character at a spawn point of its type. Ghosts are placed first, then each character take the
+// spawn point farthest from the characters already placed, so characters are spread out even if there are more
+// characters than spawn points.
+func (m *Map) Spread(players []*Player, r *rand.Rand) {
+	ordered := make([]*Player, 0, len(players))
+	for _, charType := range []CharacterType{GHOST, PLAYER} {
+		for _, p := range players {
+			if p.Character().Type() == charType {
+				ordered = append(ordered, p)
+			}
+		}
+	}
+	placed := make([]*Vector3, 0, len(ordered))
+	for _, p := range ordered {
+		spawns := m.Spawns[p.Character().Type()]
+		var best *Vector3
+		bestDistance := float32(-1)
+		// visit spawn points in random order, so ties are broken randomly
+		for _, i := range r.Perm(len(spawns)) {
+			distance := float32(math.MaxFloat32)
+			for _, other := range placed {
+				if d := spawns[i].Distance(other); d < distance {
+					distance = d
+				}
+			}
+			if distance > bestDistance {
+				best = spawns[i]
+				bestDistance = distance
+			}
+		}
+		pos := *best
+		p.Character().SetPos(&pos)
+		placed = append(placed, &pos)
+	}
+}
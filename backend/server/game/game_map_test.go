@@ -0,0 +1,97 @@
+package game
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testBounds = `"bounds": {"min": {"x": 0, "y": 0, "z": 0}, "max": {"x": 10, "y": 10, "z": 10}}`
+
+func writeMap(t *testing.T, dir string, file string, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, file)
+	err := os.WriteFile(path, []byte(content), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadMap(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name:    "valid",
+			content: `{"name": "m", "spawns": {"ghost": [{"x": 1}], "player": [{"x": 2}, {"x": 3}]}, ` + testBounds + `}`,
+		},
+		{
+			name:    "null ghost spawn",
+			content: `{"name": "m", "spawns": {"ghost": [null], "player": [{"x": 2}]}, ` + testBounds + `}`,
+			wantErr: "is null",
+		},
+		{
+			name:    "null player spawn",
+			content: `{"name": "m", "spawns": {"ghost": [{"x": 1}], "player": [{"x": 2}, null]}, ` + testBounds + `}`,
+			wantErr: "is null",
+		},
+		{
+			name:    "no spawn",
+			content: `{"name": "m", "spawns": {"ghost": [{"x": 1}], "player": []}, ` + testBounds + `}`,
+			wantErr: "no spawn point",
+		},
+		{
+			name:    "spawn out of bounds",
+			content: `{"name": "m", "spawns": {"ghost": [{"x": 11}], "player": [{"x": 2}]}, ` + testBounds + `}`,
+			wantErr: "is not allowed",
+		},
+		{
+			name:    "no name",
+			content: `{"spawns": {"ghost": [{"x": 1}], "player": [{"x": 2}]}, ` + testBounds + `}`,
+			wantErr: "no name",
+		},
+		{
+			name:    "invalid json",
+			content: `{`,
+			wantErr: "unexpected end",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeMap(t, t.TempDir(), "test.json", tt.content)
+			m, err := LoadMap(path)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if m.Name != "m" || len(m.Spawns[GHOST]) != 1 || len(m.Spawns[PLAYER]) != 2 {
+					t.Fatalf("unexpected map %+v", m)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			// the error must tell which file is broken
+			if !strings.Contains(err.Error(), path) || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want %q in %s", err, tt.wantErr, path)
+			}
+		})
+	}
+}
+
+func TestLoadMapsDuplicateName(t *testing.T) {
+	dir := t.TempDir()
+	content := `{"name": "m", "spawns": {"ghost": [{"x": 1}], "player": [{"x": 2}]}, ` + testBounds + `}`
+	writeMap(t, dir, "a.json", content)
+	writeMap(t, dir, "b.json", content)
+	_, err := LoadMaps(dir)
+	if err == nil || !strings.Contains(err.Error(), "defined twice") {
+		t.Fatalf("error = %v, want duplicate map", err)
+	}
+}
@@ -2,59 +2,82 @@ package game
 
 import (
 	"github.com/ppodds/hide-and-seek/server/player"
+	"github.com/ppodds/hide-and-seek/server/rpc"
 	"math/rand"
 	"sync"
 	"time"
 )
 
 type Games struct {
-	games map[uint32]*Game
-	curID uint32
+	games    map[uint32]*Game
+	curID    uint32
+	reliable *rpc.ReliableUDP
 	sync.RWMutex
 }
 
-func NewGames() *Games {
+func NewGames(reliable *rpc.ReliableUDP) *Games {
 	games := new(Games)
 	games.games = make(map[uint32]*Game)
+	games.reliable = reliable
 	games.curID = 1
 	return games
 }
 
-func (games *Games) CreateGame(lobbyID uint32, players []*player.Player) *Game {
-	mapPlayers := make(map[uint32]*Player)
-	s := rand.NewSource(time.Now().Unix())
+// CreateGame create a game of the players. The mode of the settings assign the roles and spawn the players.
+func (games *Games) CreateGame(lobbyID uint32, players []*player.Player, settings Settings) *Game {
+	if settings.Mode == nil {
+		settings.Mode, _ = FindMode(ClassicMode)
+	}
+	s := rand.NewSource(time.Now().UnixNano())
 	r := rand.New(s)
-	picked := r.Intn(len(players))
-	var ghost *Player
-	for i, p := range players {
+	gamePlayers := make([]*Player, 0, len(players))
+	mapPlayers := make(map[uint32]*Player)
+	for _, p := range players {
 		gamePlayer := NewPlayer(p)
+		gamePlayers = append(gamePlayers, gamePlayer)
 		mapPlayers[p.ID] = gamePlayer
-		if i == picked {
-			ghost = gamePlayer
-			gamePlayer.character.charType = GHOST
-		}
 	}
+	settings.Mode.AssignRoles(gamePlayers, settings, r)
+	for _, p := range gamePlayers {
+		p.setInitialType(p.Character().Type())
+	}
+	settings.Mode.Spawn(gamePlayers, settings.Map, r)
 	games.Lock()
 	defer games.Unlock()
-	game := NewGame(games.curID, lobbyID, mapPlayers, ghost)
+	game := NewGame(games.curID, lobbyID, mapPlayers, settings, games.reliable)
 	games.games[games.curID] = game
 	games.curID++
 	return game
 }
 
+// FindByPlayer return the game the player is in. Return nil if the player is not in any game.
+func (games *Games) FindByPlayer(id uint32) *Game {
+	for _, game := range games.Games() {
+		if _, ok := game.Players()[id]; ok {
+			return game
+		}
+	}
+	return nil
+}
+
+// Games return a copy of all games, so it is safe to iterate while games are added or removed.
 func (games *Games) Games() map[uint32]*Game {
 	games.RLock()
 	defer games.RUnlock()
-	return games.games
+	m := make(map[uint32]*Game, len(games.games))
+	for k, v := range games.games {
+		m[k] = v
+	}
+	return m
 }
 
 func (games *Games) RmGame(id uint32) bool {
 	games.Lock()
+	defer games.Unlock()
 	_, ok := games.games[id]
 	if !ok {
 		return false
 	}
 	delete(games.games, id)
-	games.Unlock()
 	return true
 }
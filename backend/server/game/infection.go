@@ -0,0 +1,16 @@
+package game
+
+func init() {
+	RegisterMode(InfectionMode, new(Infection))
+}
+
+// Infection is a mode in which caught players become ghosts. The ghosts win if every player is infected before the
+// round is over. Infected players are ghosts, so the rest of the rules are the classic ones.
+type Infection struct {
+	Classic
+}
+
+func (infection *Infection) OnCatch(game *Game, caught *Player, catcher *Player) {
+	game.Catch(caught, catcher)
+	game.SetRole(caught, GHOST)
+}
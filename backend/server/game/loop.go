@@ -0,0 +1,141 @@
+package game
+
+import (
+	"fmt"
+	"github.com/ppodds/hide-and-seek/protos"
+	"github.com/ppodds/hide-and-seek/server/rpc"
+	"google.golang.org/protobuf/proto"
+	"time"
+)
+
+// Run start the simulation loop of the game in a new goroutine. The loop advance the game by the tick rate of the
+// settings until the game is over, then onEnd is called.
+func (game *Game) Run(onEnd func(game *Game)) {
+	tickRate := game.settings.TickRate
+	if tickRate == 0 {
+		tickRate = 1
+	}
+	game.Lock()
+	game.startFrom = time.Now()
+	game.Unlock()
+	go func() {
+		ticker := time.NewTicker(time.Second / time.Duration(tickRate))
+		defer ticker.Stop()
+		for range ticker.C {
+			if game.step() {
+				onEnd(game)
+				return
+			}
+		}
+	}()
+}
+
+// step advance the game by one tick. Return true if the game is over.
+func (game *Game) step() bool {
+	game.Lock()
+	game.tick++
+	game.Unlock()
+	now := time.Now()
+	players := game.Players()
+	for id, character := range game.drainInputs() {
+		p, ok := players[id]
+		if !ok {
+			continue
+		}
+		game.applyInput(p, character, now)
+	}
+	game.settings.Mode.OnTick(game, now)
+	game.detectCatches()
+	winner, over := game.settings.Mode.CheckEnd(game)
+	if over {
+		scores := game.settings.Mode.Score(game)
+		game.Lock()
+		game.winner = winner
+		game.scores = scores
+		game.Unlock()
+		game.broadcast(&protos.GameBroadcast{
+			Event:  protos.GameEvent_GAME_OVER,
+			Winner: &winner,
+			Scores: game.Scores(),
+		})
+		return true
+	}
+	game.sendSnapshots()
+	return false
+}
+
+// detectCatches find every living player within the catch radius of a ghost and let the mode handle the catch. The
+// catch is credited to the nearest ghost.
+func (game *Game) detectCatches() {
+	ghosts := game.Ghosts()
+	if len(ghosts) == 0 {
+		return
+	}
+	for id, p := range game.Players() {
+		if _, ok := ghosts[id]; ok || p.Character().Dead() {
+			continue
+		}
+		pos := p.Character().Pos()
+		var catcher *Player
+		nearest := game.settings.CatchRadius
+		for _, ghost := range ghosts {
+			distance := ghost.Character().Pos().Distance(pos)
+			if distance <= nearest {
+				catcher = ghost
+				nearest = distance
+			}
+		}
+		if catcher == nil {
+			continue
+		}
+		game.settings.Mode.OnCatch(game, p, catcher)
+	}
+}
+
+// Catch credit the catcher and notify every player that the player is caught. Modes call it from OnCatch.
+func (game *Game) Catch(caught *Player, catcher *Player) {
+	caught.setCaughtAt(time.Now())
+	catcher.AddCatch()
+	data, err := caught.MarshalProtoBuf()
+	if err != nil {
+		fmt.Println("failed to marshal caught player", caught.Player().ID, "because", err)
+		return
+	}
+	game.broadcast(&protos.GameBroadcast{
+		Event:  protos.GameEvent_PLAYER_CAUGHT,
+		Player: data,
+	})
+}
+
+// broadcast send a critical event to every player through the reliable channel.
+func (game *Game) broadcast(msg *protos.GameBroadcast) {
+	data, err := proto.Marshal(msg)
+	if err != nil {
+		fmt.Println("skip broadcast of game", game.id, "because", err)
+		return
+	}
+	for _, p := range game.Players() {
+		conn := p.Player().UDPConn()
+		addr := p.Player().UDPAddr()
+		if conn == nil || addr == nil {
+			continue
+		}
+		err = game.reliable.Send(conn, addr, data)
+		if err != nil {
+			fmt.Println("skip broadcast to player", p.Player().ID, "because", err)
+		}
+	}
+}
+
+// send send data to the player through the unreliable channel.
+func (game *Game) send(p *Player, data []byte) {
+	conn := p.Player().UDPConn()
+	addr := p.Player().UDPAddr()
+	if conn == nil || addr == nil {
+		return
+	}
+	err := rpc.SendUDPRes(conn, addr, data)
+	if err != nil {
+		fmt.Println("skip broadcast to player", p.Player().ID, "because", err)
+	}
+}
@@ -0,0 +1,108 @@
+package game
+
+import (
+	"github.com/ppodds/hide-and-seek/protos"
+	"github.com/ppodds/hide-and-seek/server/player"
+	"math/rand"
+	"sort"
+	"sync"
+)
+
+// Match is a series of games played by a lobby. Points add up over the rounds, and the ghost role rotates to the players
+// who were the ghost the fewest times. The rotation is best-effort: everyone only get a turn if there are enough rounds
+// for it, and players who join or sit out rounds are not compensated.
+type Match struct {
+	rounds     uint32
+	round      uint32
+	players    map[uint32]*player.Player
+	totals     map[uint32]uint32
+	last       map[uint32]uint32
+	ghostTurns map[uint32]uint32
+	sync.RWMutex
+}
+
+func NewMatch(rounds uint32) *Match {
+	match := new(Match)
+	match.rounds = rounds
+	match.players = make(map[uint32]*player.Player)
+	match.totals = make(map[uint32]uint32)
+	match.last = make(map[uint32]uint32)
+	match.ghostTurns = make(map[uint32]uint32)
+	return match
+}
+
+// GhostOrder return the ids of the players, who were the ghost the fewest times first. Ties are broken randomly.
+func (match *Match) GhostOrder(players []*player.Player, r *rand.Rand) []uint32 {
+	match.RLock()
+	defer match.RUnlock()
+	order := make([]uint32, len(players))
+	for i, picked := range r.Perm(len(players)) {
+		order[i] = players[picked].ID
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return match.ghostTurns[order[i]] < match.ghostTurns[order[j]]
+	})
+	return order
+}
+
+// StartRound count the round and the ghost turns of the game.
+func (match *Match) StartRound(game *Game) {
+	match.Lock()
+	defer match.Unlock()
+	match.round++
+	for id, p := range game.Players() {
+		match.players[id] = p.Player()
+	}
+	for id := range game.Ghosts() {
+		match.ghostTurns[id]++
+	}
+}
+
+// EndRound add the scores of the game to the totals.
+func (match *Match) EndRound(game *Game) {
+	match.Lock()
+	defer match.Unlock()
+	match.last = game.Scores()
+	for id, score := range match.last {
+		match.totals[id] += score
+	}
+}
+
+func (match *Match) Round() uint32 {
+	match.RLock()
+	defer match.RUnlock()
+	return match.round
+}
+
+// Over report whether every round is played.
+func (match *Match) Over() bool {
+	match.RLock()
+	defer match.RUnlock()
+	return match.round >= match.rounds
+}
+
+// MarshalProtoBuf return the scoreboard of the match, including players who left.
+func (match *Match) MarshalProtoBuf() (*protos.Scoreboard, error) {
+	match.RLock()
+	defer match.RUnlock()
+	entries := make([]*protos.ScoreEntry, 0, len(match.players))
+	for id, p := range match.players {
+		data, err := p.MarshalProtoBuf()
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, &protos.ScoreEntry{
+			Player:      data,
+			Points:      match.totals[id],
+			RoundPoints: match.last[id],
+			GhostTurns:  match.ghostTurns[id],
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Points != entries[j].Points {
+			return entries[i].Points > entries[j].Points
+		}
+		return entries[i].Player.Id < entries[j].Player.Id
+	})
+	return &protos.Scoreboard{Round: match.round, Rounds: match.rounds, Entries: entries}, nil
+}
@@ -0,0 +1,55 @@
+package game
+
+import (
+	"github.com/ppodds/hide-and-seek/protos"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+const (
+	ClassicMode   = "classic"
+	InfectionMode = "infection"
+)
+
+// GameMode is a rule set of the game. A mode is registered by name with RegisterMode, and lobbies choose it by name.
+// Modes are shared by every game, so they must keep their state in the game.
+type GameMode interface {
+	// AssignRoles set the character type of every player before the game start.
+	AssignRoles(players []*Player, settings Settings, r *rand.Rand)
+	// Spawn set the position of every player before the game start.
+	Spawn(players []*Player, m *Map, r *rand.Rand)
+	// OnTick is called on every tick, after the inputs are applied and before catches are detected.
+	OnTick(game *Game, now time.Time)
+	// OnCatch is called when the catcher catch the player.
+	OnCatch(game *Game, caught *Player, catcher *Player)
+	// CheckEnd return the winner if the game is over.
+	CheckEnd(game *Game) (protos.CharacterType, bool)
+	// Score return the points of every player when the game is over.
+	Score(game *Game) map[uint32]uint32
+}
+
+var modes = make(map[string]GameMode)
+
+// RegisterMode make the mode available to lobbies. It should be called in init, and a name can't be registered twice.
+func RegisterMode(name string, mode GameMode) {
+	if _, ok := modes[name]; ok {
+		panic("game mode " + name + " is registered twice")
+	}
+	modes[name] = mode
+}
+
+func FindMode(name string) (GameMode, bool) {
+	mode, ok := modes[name]
+	return mode, ok
+}
+
+// ModeNames return the names of the registered modes in order.
+func ModeNames() []string {
+	names := make([]string, 0, len(modes))
+	for name := range modes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
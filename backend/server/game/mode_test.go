@@ -0,0 +1,98 @@
+package game_test
+
+import (
+	"github.com/ppodds/hide-and-seek/protos"
+	"github.com/ppodds/hide-and-seek/server/game"
+	"github.com/ppodds/hide-and-seek/server/player"
+	"github.com/ppodds/hide-and-seek/server/rpc"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+const tagMode = "tag"
+
+func init() {
+	game.RegisterMode(tagMode, new(tag))
+}
+
+// tag is a mode written outside package game, so it can only use the exported hooks. The ghost swap its role with the
+// player it catches, and the game is over after the first catch.
+type tag struct {
+	game.Classic
+}
+
+// Spawn put every player at the same position, so the first tick catch someone.
+func (tag *tag) Spawn(players []*game.Player, m *game.Map, r *rand.Rand) {
+	for _, p := range players {
+		p.SetCharacter(&protos.Character{
+			Pos:      &protos.Vector3{},
+			Rotation: &protos.Vector3{},
+			Velocity: &protos.Vector3{},
+		})
+	}
+}
+
+func (tag *tag) OnCatch(g *game.Game, caught *game.Player, catcher *game.Player) {
+	if caught.Character().Type() == game.GHOST {
+		return
+	}
+	g.Catch(caught, catcher)
+	g.SetRole(caught, game.GHOST)
+	g.SetRole(catcher, game.PLAYER)
+}
+
+func (tag *tag) CheckEnd(g *game.Game) (protos.CharacterType, bool) {
+	for _, p := range g.Players() {
+		if p.Catches() != 0 {
+			return protos.CharacterType_GHOST, true
+		}
+	}
+	if time.Since(g.StartFrom()) > g.Settings().RoundDuration {
+		return protos.CharacterType_PLAYER, true
+	}
+	return protos.CharacterType_PLAYER, false
+}
+
+func TestModeOutsidePackage(t *testing.T) {
+	mode, ok := game.FindMode(tagMode)
+	if !ok {
+		t.Fatal("tag mode is not registered")
+	}
+	ghost := player.NewPlayer(1, "ghost", "", nil)
+	runner := player.NewPlayer(2, "runner", "", nil)
+	games := game.NewGames(rpc.NewReliableUDP())
+	g := games.CreateGame(1, []*player.Player{ghost, runner}, game.Settings{
+		TickRate:      100,
+		CatchRadius:   1,
+		Ghosts:        1,
+		GhostOrder:    []uint32{ghost.ID},
+		RoundDuration: time.Second,
+		Mode:          mode,
+	})
+	if _, ok := g.Ghosts()[ghost.ID]; !ok {
+		t.Fatalf("ghosts = %v, want player %d", g.Ghosts(), ghost.ID)
+	}
+	done := make(chan struct{})
+	g.Run(func(g *game.Game) {
+		close(done)
+	})
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("game is not over")
+	}
+	if g.Winner() != protos.CharacterType_GHOST {
+		t.Fatalf("winner = %v, want ghost", g.Winner())
+	}
+	players := g.Players()
+	if players[ghost.ID].Catches() != 1 {
+		t.Fatalf("catches = %d, want 1", players[ghost.ID].Catches())
+	}
+	if _, ok := g.Ghosts()[runner.ID]; !ok || len(g.Ghosts()) != 1 {
+		t.Fatalf("ghosts = %v, want only player %d", g.Ghosts(), runner.ID)
+	}
+	if players[ghost.ID].InitialType() != game.GHOST || players[ghost.ID].Character().Type() != game.PLAYER {
+		t.Fatal("roles are not swapped")
+	}
+}
@@ -0,0 +1,86 @@
+package game
+
+import (
+	"fmt"
+	"github.com/ppodds/hide-and-seek/protos"
+	"time"
+)
+
+// moveTolerance is the extra distance allowed for each update to absorb network jitter.
+const moveTolerance = 0.5
+
+// applyInput validate the character state sent by the player and apply it. Illegal moves are clamped to the max
+// distance the character can travel, moves to positions the map doesn't allow and states with NaN or infinite
+// components are rejected, and the player is kicked after too many violations.
+func (game *Game) applyInput(p *Player, character *protos.Character, now time.Time) {
+	if p.Character().Dead() {
+		return
+	}
+	pos := ProtobufToVector3(character.Pos)
+	rotation := ProtobufToVector3(character.Rotation)
+	velocity := ProtobufToVector3(character.Velocity)
+	if !pos.Finite() || !rotation.Finite() || !velocity.Finite() {
+		game.violate(p)
+		return
+	}
+	maxSpeed, ok := game.settings.MaxSpeed[p.Character().Type()]
+	if !ok {
+		p.SetCharacter(character)
+		return
+	}
+	inMap := game.settings.Map.Allowed(pos)
+	if !inMap {
+		pos = p.Character().Pos()
+	}
+	legal := p.Character().Move(pos, rotation, velocity, maxSpeed, now)
+	if legal && inMap {
+		return
+	}
+	game.violate(p)
+}
+
+// violate count an illegal move of the player and kick it after too many violations.
+func (game *Game) violate(p *Player) {
+	violations := p.AddViolation()
+	fmt.Println("reject illegal move of player", p.Player().ID, "violations:", violations)
+	if game.settings.KickViolations != 0 && violations >= game.settings.KickViolations {
+		game.kick(p, "too many illegal moves")
+	}
+}
+
+// kick notify every player and remove the player from the game.
+func (game *Game) kick(p *Player, reason string) {
+	data, err := p.MarshalProtoBuf()
+	if err != nil {
+		fmt.Println("failed to marshal kicked player", p.Player().ID, "because", err)
+	} else {
+		game.broadcast(&protos.GameBroadcast{
+			Event:  protos.GameEvent_PLAYER_KICKED,
+			Player: data,
+			Reason: &reason,
+		})
+	}
+	game.RmPlayer(p.Player().ID)
+}
+
+// Move update the character if the target position is reachable with maxSpeed since the last update. Otherwise, the
+// character is moved toward the target as far as it can. Return true if the move is legal.
+func (character *Character) Move(pos *Vector3, rotation *Vector3, velocity *Vector3, maxSpeed float32, now time.Time) bool {
+	character.Lock()
+	defer character.Unlock()
+	elapsed := float32(now.Sub(character.updatedAt).Seconds())
+	maxDistance := maxSpeed*elapsed + moveTolerance
+	distance := character.pos.Distance(pos)
+	legal := distance <= maxDistance
+	if !legal {
+		pos = character.pos.MoveToward(pos, maxDistance)
+	}
+	if velocity.Length() > maxSpeed {
+		velocity = velocity.Scale(maxSpeed / velocity.Length())
+	}
+	character.pos = pos
+	character.rotation = rotation
+	character.velocity = velocity
+	character.updatedAt = now
+	return legal
+}
@@ -0,0 +1,54 @@
+package game
+
+import (
+	"github.com/ppodds/hide-and-seek/protos"
+	"github.com/ppodds/hide-and-seek/server/player"
+	"github.com/ppodds/hide-and-seek/server/rpc"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestApplyInputNonFinite(t *testing.T) {
+	nan := float32(math.NaN())
+	inf := float32(math.Inf(1))
+	tests := []struct {
+		name     string
+		pos      *protos.Vector3
+		rotation *protos.Vector3
+		velocity *protos.Vector3
+		legal    bool
+	}{
+		{name: "finite", pos: &protos.Vector3{X: 1}, rotation: &protos.Vector3{}, velocity: &protos.Vector3{X: 1}, legal: true},
+		{name: "nan position", pos: &protos.Vector3{X: nan}, rotation: &protos.Vector3{}, velocity: &protos.Vector3{}},
+		{name: "inf position", pos: &protos.Vector3{Z: -inf}, rotation: &protos.Vector3{}, velocity: &protos.Vector3{}},
+		{name: "nan rotation", pos: &protos.Vector3{}, rotation: &protos.Vector3{Y: nan}, velocity: &protos.Vector3{}},
+		{name: "inf rotation", pos: &protos.Vector3{}, rotation: &protos.Vector3{X: inf}, velocity: &protos.Vector3{}},
+		{name: "nan velocity", pos: &protos.Vector3{}, rotation: &protos.Vector3{}, velocity: &protos.Vector3{Z: nan}},
+		{name: "inf velocity", pos: &protos.Vector3{}, rotation: &protos.Vector3{}, velocity: &protos.Vector3{X: inf}},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewPlayer(player.NewPlayer(1, "p", "", nil))
+			game := NewGame(1, 1, map[uint32]*Player{1: p}, Settings{
+				Map:      &Map{Bounds: Box{Min: Vector3{X: -10, Y: -10, Z: -10}, Max: Vector3{X: 10, Y: 10, Z: 10}}},
+				MaxSpeed: map[CharacterType]float32{PLAYER: 5},
+			}, rpc.NewReliableUDP())
+			game.applyInput(p, &protos.Character{Pos: tt.pos, Rotation: tt.rotation, Velocity: tt.velocity}, time.Now().Add(time.Second))
+			violations := p.AddViolation() - 1
+			if tt.legal && violations != 0 {
+				t.Fatalf("legal input counted as %d violations", violations)
+			}
+			if !tt.legal && violations != 1 {
+				t.Fatalf("violations = %d, want 1", violations)
+			}
+			character := p.Character()
+			character.RLock()
+			defer character.RUnlock()
+			if !character.pos.Finite() || !character.rotation.Finite() || !character.velocity.Finite() {
+				t.Fatal("non-finite input is stored")
+			}
+		})
+	}
+}
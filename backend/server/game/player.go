@@ -3,11 +3,20 @@ package game
 import (
 	"github.com/ppodds/hide-and-seek/protos"
 	"github.com/ppodds/hide-and-seek/server/player"
+	"sync"
+	"time"
 )
 
 type Player struct {
-	player    *player.Player
-	character *Character
+	player     *player.Player
+	character  *Character
+	violations uint
+	catches    uint32
+	// initialType is the role assigned when the game is created. The character type may change during the game.
+	initialType CharacterType
+	// caughtAt is the time the player is caught. It is zero if the player is not caught.
+	caughtAt time.Time
+	sync.Mutex
 }
 
 func NewPlayer(player *player.Player) *Player {
@@ -25,6 +34,52 @@ func (player *Player) Character() *Character {
 	return player.character
 }
 
+// AddViolation count an illegal move of the player. Return the number of violations so far.
+func (player *Player) AddViolation() uint {
+	player.Lock()
+	defer player.Unlock()
+	player.violations++
+	return player.violations
+}
+
+func (player *Player) AddCatch() {
+	player.Lock()
+	defer player.Unlock()
+	player.catches++
+}
+
+// Catches return the number of players caught by the player.
+func (player *Player) Catches() uint32 {
+	player.Lock()
+	defer player.Unlock()
+	return player.catches
+}
+
+func (player *Player) setCaughtAt(t time.Time) {
+	player.Lock()
+	defer player.Unlock()
+	player.caughtAt = t
+}
+
+func (player *Player) CaughtAt() time.Time {
+	player.Lock()
+	defer player.Unlock()
+	return player.caughtAt
+}
+
+func (player *Player) setInitialType(charType CharacterType) {
+	player.Lock()
+	defer player.Unlock()
+	player.initialType = charType
+}
+
+// InitialType return the role assigned to the player when the game is created.
+func (player *Player) InitialType() CharacterType {
+	player.Lock()
+	defer player.Unlock()
+	return player.initialType
+}
+
 func (player *Player) SetCharacter(character *protos.Character) {
 	player.character.FromProtobuf(character)
 }
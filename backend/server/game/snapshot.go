@@ -0,0 +1,109 @@
+package game
+
+import (
+	"fmt"
+	"github.com/ppodds/hide-and-seek/protos"
+	"google.golang.org/protobuf/proto"
+)
+
+// snapshotHistory is the number of past snapshots kept as delta baselines.
+const snapshotHistory = 64
+
+// characterState is a copy of a character at a tick.
+type characterState struct {
+	charType CharacterType
+	dead     bool
+	pos      Vector3
+	rotation Vector3
+	velocity Vector3
+}
+
+func (character *Character) state() characterState {
+	character.RLock()
+	defer character.RUnlock()
+	return characterState{
+		charType: character.charType,
+		dead:     character.dead,
+		pos:      *character.pos,
+		rotation: *character.rotation,
+		velocity: *character.velocity,
+	}
+}
+
+// delta return the fields changed since baseline. A nil baseline means every field is changed.
+// Return nil if nothing changed.
+func (state *characterState) delta(id uint32, baseline *characterState) *protos.CharacterDelta {
+	delta := &protos.CharacterDelta{Id: id}
+	changed := false
+	if baseline == nil || state.charType != baseline.charType {
+		charType := state.charType.MarshalProtoBuf()
+		delta.Type = &charType
+		changed = true
+	}
+	if baseline == nil || state.dead != baseline.dead {
+		dead := state.dead
+		delta.Dead = &dead
+		changed = true
+	}
+	if baseline == nil || state.pos != baseline.pos {
+		delta.Pos, _ = state.pos.MarshalProtoBuf()
+		changed = true
+	}
+	if baseline == nil || state.rotation != baseline.rotation {
+		delta.Rotation, _ = state.rotation.MarshalProtoBuf()
+		changed = true
+	}
+	if baseline == nil || state.velocity != baseline.velocity {
+		delta.Velocity, _ = state.velocity.MarshalProtoBuf()
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	return delta
+}
+
+// sendSnapshots record the state of the current tick and send each player a snapshot encoded against the latest
+// snapshot the player acknowledged.
+func (game *Game) sendSnapshots() {
+	players := game.Players()
+	states := make(map[uint32]characterState, len(players))
+	for id, p := range players {
+		states[id] = p.Character().state()
+	}
+	game.history[game.tick] = states
+	delete(game.history, game.tick-snapshotHistory)
+
+	for id, p := range players {
+		snapshot := &protos.GameSnapshot{Tick: game.tick}
+		ack := game.ackOf(id)
+		baseline, ok := game.history[ack]
+		if ok {
+			snapshot.Baseline = ack
+		}
+		for playerID, state := range states {
+			var base *characterState
+			if b, ok := baseline[playerID]; ok {
+				base = &b
+			}
+			delta := state.delta(playerID, base)
+			if delta != nil {
+				snapshot.Characters = append(snapshot.Characters, delta)
+			}
+		}
+		for playerID := range baseline {
+			if _, ok := states[playerID]; !ok {
+				snapshot.Removed = append(snapshot.Removed, playerID)
+			}
+		}
+		data, err := proto.Marshal(&protos.GameBroadcast{
+			Event:    protos.GameEvent_SNAPSHOT,
+			Snapshot: snapshot,
+		})
+		if err != nil {
+			fmt.Println("skip snapshot to player", id, "because", err)
+			continue
+		}
+		game.send(p, data)
+	}
+}
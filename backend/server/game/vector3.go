@@ -2,6 +2,7 @@ package game
 
 import (
 	"github.com/ppodds/hide-and-seek/protos"
+	"math"
 )
 
 type Vector3 struct {
@@ -25,3 +26,43 @@ func (v *Vector3) MarshalProtoBuf() (*protos.Vector3, error) {
 		Z: v.Z,
 	}, nil
 }
+
+// Finite report whether no component is NaN or infinite.
+func (v *Vector3) Finite() bool {
+	for _, c := range []float32{v.X, v.Y, v.Z} {
+		f := float64(c)
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return false
+		}
+	}
+	return true
+}
+
+func (v *Vector3) Distance(other *Vector3) float32 {
+	dx := float64(v.X - other.X)
+	dy := float64(v.Y - other.Y)
+	dz := float64(v.Z - other.Z)
+	return float32(math.Sqrt(dx*dx + dy*dy + dz*dz))
+}
+
+func (v *Vector3) Length() float32 {
+	return v.Distance(&Vector3{})
+}
+
+func (v *Vector3) Scale(factor float32) *Vector3 {
+	return &Vector3{X: v.X * factor, Y: v.Y * factor, Z: v.Z * factor}
+}
+
+// MoveToward return the point reached by moving from v toward target for at most distance.
+func (v *Vector3) MoveToward(target *Vector3, distance float32) *Vector3 {
+	total := v.Distance(target)
+	if total <= distance || total == 0 {
+		return target
+	}
+	ratio := distance / total
+	return &Vector3{
+		X: v.X + (target.X-v.X)*ratio,
+		Y: v.Y + (target.Y-v.Y)*ratio,
+		Z: v.Z + (target.Z-v.Z)*ratio,
+	}
+}
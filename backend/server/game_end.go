@@ -0,0 +1,76 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ppodds/hide-and-seek/protos"
+	"github.com/ppodds/hide-and-seek/server/account"
+	"github.com/ppodds/hide-and-seek/server/game"
+)
+
+// EndGame is called when a game is over. It record the result to the accounts and the match of the lobby. The next
+// round starts after the intermission, or the lobby is reopened if the match is over.
+func (app *App) EndGame(g *game.Game) {
+	app.Games.RmGame(g.ID())
+	app.recordGame(g)
+	// the next game start from a new handshake, so the reliable state is dropped once GAME_OVER is acked
+	for _, p := range g.Players() {
+		if addr := p.Player().UDPAddr(); addr != nil {
+			app.Reliable.Release(addr)
+		}
+	}
+	lobby, ok := app.Lobbies.Lobbies()[g.LobbyID()]
+	if !ok {
+		return
+	}
+	match := lobby.Match()
+	if match == nil {
+		lobby.SetInGame(false)
+		lobby.ResetReady()
+		return
+	}
+	match.EndRound(g)
+	if match.Over() || lobby.CurPeople() < 2 {
+		app.endMatch(lobby)
+		return
+	}
+	scoreboard, err := match.MarshalProtoBuf()
+	if err != nil {
+		fmt.Println("failed to marshal scoreboard of lobby", lobby.ID, "because", err)
+	} else {
+		err = Push(lobby.Players(), &protos.LobbyBroadcast{Event: protos.LobbyEvent_ROUND_OVER, Scoreboard: scoreboard})
+		if err != nil {
+			fmt.Println("failed to push scoreboard of lobby", lobby.ID, "because", err)
+		}
+	}
+	time.AfterFunc(app.Config.RoundIntermission, func() {
+		app.nextRound(lobby)
+	})
+}
+
+func (app *App) recordGame(g *game.Game) {
+	for _, p := range g.Players() {
+		acc, err := app.Accounts.Get(p.Player().Name())
+		if errors.Is(err, account.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			fmt.Println("skip recording player", p.Player().ID, "because", err)
+			continue
+		}
+		acc.Stats.Games++
+		// infected players change role during the game, so the result follow the role they start with
+		if p.InitialType().MarshalProtoBuf() == g.Winner() {
+			acc.Stats.Wins++
+		} else {
+			acc.Stats.Losses++
+		}
+		acc.Stats.Catches += p.Catches()
+		err = app.Accounts.Save(acc)
+		if err != nil {
+			fmt.Println("skip recording player", p.Player().ID, "because", err)
+		}
+	}
+}
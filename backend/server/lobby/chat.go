@@ -0,0 +1,48 @@
+package lobby
+
+import (
+	"github.com/ppodds/hide-and-seek/protos"
+	"github.com/ppodds/hide-and-seek/server/player"
+	"time"
+)
+
+type ChatMessage struct {
+	Sender *player.Player
+	Text   string
+	SentAt time.Time
+	// Team is true if the message is only sent to the teammates of the sender.
+	Team bool
+}
+
+func (msg *ChatMessage) MarshalProtoBuf() (*protos.ChatMessage, error) {
+	sender, err := msg.Sender.MarshalProtoBuf()
+	if err != nil {
+		return nil, err
+	}
+	return &protos.ChatMessage{Sender: sender, Text: msg.Text, SentAt: msg.SentAt.UnixMilli(), Team: msg.Team}, nil
+}
+
+// AddChat keep the message in the history of the lobby. Only the latest limit messages are kept.
+func (lobby *Lobby) AddChat(msg *ChatMessage, limit uint) {
+	lobby.Lock()
+	defer lobby.Unlock()
+	lobby.chat = append(lobby.chat, msg)
+	if uint(len(lobby.chat)) > limit {
+		lobby.chat = append([]*ChatMessage(nil), lobby.chat[uint(len(lobby.chat))-limit:]...)
+	}
+}
+
+// MarshalChat return the history of the lobby, oldest first.
+func (lobby *Lobby) MarshalChat() ([]*protos.ChatMessage, error) {
+	lobby.RLock()
+	defer lobby.RUnlock()
+	chat := make([]*protos.ChatMessage, 0, len(lobby.chat))
+	for _, msg := range lobby.chat {
+		data, err := msg.MarshalProtoBuf()
+		if err != nil {
+			return nil, err
+		}
+		chat = append(chat, data)
+	}
+	return chat, nil
+}
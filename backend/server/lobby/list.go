@@ -0,0 +1,119 @@
+package lobby
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/ppodds/hide-and-seek/protos"
+	"sort"
+)
+
+const (
+	DefaultListLimit = 20
+	MaxListLimit     = 100
+)
+
+// listCursor is the position of the last lobby of a page. Lobbies are ordered by key, then by id.
+type listCursor struct {
+	key uint32
+	id  uint32
+}
+
+func (cursor listCursor) String() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d.%d", cursor.key, cursor.id)))
+}
+
+func parseListCursor(s string) (listCursor, error) {
+	var cursor listCursor
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return cursor, errors.New("invalid cursor")
+	}
+	_, err = fmt.Sscanf(string(data), "%d.%d", &cursor.key, &cursor.id)
+	if err != nil {
+		return cursor, errors.New("invalid cursor")
+	}
+	return cursor, nil
+}
+
+func sortKey(summary *protos.LobbySummary, order protos.LobbySort) uint32 {
+	switch order {
+	case protos.LobbySort_MOST_PLAYERS, protos.LobbySort_FEWEST_PLAYERS:
+		return summary.CurPeople
+	default:
+		return summary.Id
+	}
+}
+
+// less report whether a is listed before b.
+func less(a listCursor, b listCursor, order protos.LobbySort) bool {
+	if a.key != b.key {
+		if order == protos.LobbySort_NEWEST || order == protos.LobbySort_MOST_PLAYERS {
+			return a.key > b.key
+		}
+		return a.key < b.key
+	}
+	return a.id < b.id
+}
+
+func matchFilter(summary *protos.LobbySummary, req *protos.LobbyListRequest) bool {
+	if req.NotFull && summary.CurPeople >= summary.MaxPeople {
+		return false
+	}
+	if req.NotInGame && summary.InGame {
+		return false
+	}
+	if req.Map != nil && summary.Map != *req.Map {
+		return false
+	}
+	if req.HasPassword != nil && summary.HasPassword != *req.HasPassword {
+		return false
+	}
+	return true
+}
+
+// List return a page of the public lobbies matching the request. Private lobbies are never listed.
+func (lobbies *Lobbies) List(req *protos.LobbyListRequest) (*protos.LobbyListResponse, error) {
+	limit := req.Limit
+	if limit == 0 {
+		limit = DefaultListLimit
+	}
+	if limit > MaxListLimit {
+		limit = MaxListLimit
+	}
+	var after *listCursor
+	if req.Cursor != "" {
+		cursor, err := parseListCursor(req.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		after = &cursor
+	}
+	summaries := make([]*protos.LobbySummary, 0)
+	for _, lobby := range lobbies.Lobbies() {
+		if lobby.Settings().Private {
+			continue
+		}
+		summary := lobby.MarshalSummary()
+		if !matchFilter(summary, req) {
+			continue
+		}
+		if after != nil && !less(*after, listCursor{sortKey(summary, req.Sort), summary.Id}, req.Sort) {
+			continue
+		}
+		summaries = append(summaries, summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		a := listCursor{sortKey(summaries[i], req.Sort), summaries[i].Id}
+		b := listCursor{sortKey(summaries[j], req.Sort), summaries[j].Id}
+		return less(a, b, req.Sort)
+	})
+	res := &protos.LobbyListResponse{Success: true}
+	if uint32(len(summaries)) > limit {
+		summaries = summaries[:limit]
+		last := summaries[limit-1]
+		res.NextCursor = listCursor{sortKey(last, req.Sort), last.Id}.String()
+	}
+	res.Lobbies = summaries
+	return res, nil
+}
@@ -0,0 +1,141 @@
+package lobby
+
+import (
+	"fmt"
+	"github.com/ppodds/hide-and-seek/protos"
+	"github.com/ppodds/hide-and-seek/server/player"
+	"testing"
+)
+
+func TestListCursor(t *testing.T) {
+	tests := []listCursor{{0, 0}, {3, 17}, {4294967295, 4294967295}}
+	for _, cursor := range tests {
+		got, err := parseListCursor(cursor.String())
+		if err != nil {
+			t.Fatalf("parse %v: %v", cursor, err)
+		}
+		if got != cursor {
+			t.Fatalf("got %v, want %v", got, cursor)
+		}
+	}
+	for _, s := range []string{"!!", "bm90IGEgY3Vyc29y", ""} {
+		if _, err := parseListCursor(s); err == nil {
+			t.Fatalf("cursor %q is accepted", s)
+		}
+	}
+}
+
+// newTestLobbies create lobbies with 1, 2, ... players in order, so the ids and player counts are both ascending.
+func newTestLobbies(t *testing.T, n int) *Lobbies {
+	t.Helper()
+	lobbies := NewLobbys()
+	id := uint32(1)
+	for i := 0; i < n; i++ {
+		settings := testDefaults
+		settings.MaxPlayers = 8
+		lobby, err := lobbies.AddLobby(player.NewPlayer(id, fmt.Sprint("p", id), "", nil), settings, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		id++
+		for j := 0; j < i; j++ {
+			if _, err = lobby.AddPlayer(player.NewPlayer(id, fmt.Sprint("p", id), "", nil)); err != nil {
+				t.Fatal(err)
+			}
+			id++
+		}
+	}
+	return lobbies
+}
+
+func TestListPaging(t *testing.T) {
+	lobbies := newTestLobbies(t, 5)
+	tests := []struct {
+		sort protos.LobbySort
+		want []uint32
+	}{
+		{protos.LobbySort_OLDEST, []uint32{1, 2, 3, 4, 5}},
+		{protos.LobbySort_NEWEST, []uint32{5, 4, 3, 2, 1}},
+		{protos.LobbySort_MOST_PLAYERS, []uint32{5, 4, 3, 2, 1}},
+		{protos.LobbySort_FEWEST_PLAYERS, []uint32{1, 2, 3, 4, 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sort.String(), func(t *testing.T) {
+			got := make([]uint32, 0)
+			pages := 0
+			cursor := ""
+			for {
+				res, err := lobbies.List(&protos.LobbyListRequest{Sort: tt.sort, Limit: 2, Cursor: cursor})
+				if err != nil {
+					t.Fatal(err)
+				}
+				pages++
+				for _, summary := range res.Lobbies {
+					got = append(got, summary.Id)
+				}
+				if res.NextCursor == "" {
+					break
+				}
+				cursor = res.NextCursor
+			}
+			if pages != 3 {
+				t.Fatalf("pages = %d, want 3", pages)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListFilter(t *testing.T) {
+	lobbies := newTestLobbies(t, 3)
+	private := testDefaults
+	private.Private = true
+	if _, err := lobbies.AddLobby(player.NewPlayer(100, "private", "", nil), private, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := lobbies.AddLobby(player.NewPlayer(101, "locked", "", nil), testDefaults, "secret"); err != nil {
+		t.Fatal(err)
+	}
+	hasPassword := true
+	tests := []struct {
+		name string
+		req  *protos.LobbyListRequest
+		want int
+	}{
+		{"public", &protos.LobbyListRequest{}, 4},
+		{"has password", &protos.LobbyListRequest{HasPassword: &hasPassword}, 1},
+		{"limit", &protos.LobbyListRequest{Limit: 1}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := lobbies.List(tt.req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(res.Lobbies) != tt.want {
+				t.Fatalf("got %d lobbies, want %d", len(res.Lobbies), tt.want)
+			}
+		})
+	}
+}
+
+func TestListInvalidCursor(t *testing.T) {
+	lobbies := newTestLobbies(t, 1)
+	if _, err := lobbies.List(&protos.LobbyListRequest{Cursor: "!!"}); err == nil {
+		t.Fatal("invalid cursor is accepted")
+	}
+}
+
+func TestListSummarySettings(t *testing.T) {
+	lobbies := newTestLobbies(t, 1)
+	res, err := lobbies.List(&protos.LobbyListRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	summary := res.Lobbies[0]
+	if summary.RoundDuration != 180 || summary.Ghosts != 1 || summary.CatchRadius != 1 || summary.Mode != "classic" || summary.Rounds != 1 {
+		t.Fatalf("summary doesn't carry the settings: %v", summary)
+	}
+}
@@ -1,61 +1,105 @@
 package lobby
 
 import (
-	"github.com/ppodds/hide-and-seek/protos"
+	"crypto/rand"
 	"github.com/ppodds/hide-and-seek/server/player"
+	"strings"
 	"sync"
 )
 
+const inviteCodeSize = 6
+
+// inviteCodeAlphabet leave out characters which are easy to mix up, like 0 and O.
+const inviteCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
 type Lobbies struct {
 	sync.RWMutex
 	curID   uint32
 	lobbies map[uint32]*Lobby
+	// codes map invite codes to lobby ids
+	codes map[string]uint32
 }
 
 func NewLobbys() *Lobbies {
 	lobbies := new(Lobbies)
 	lobbies.lobbies = make(map[uint32]*Lobby)
+	lobbies.codes = make(map[string]uint32)
 	lobbies.curID = 1
 	return lobbies
 }
 
-func (lobbies *Lobbies) AddLobby(lead *player.Player, maxNum uint32) *Lobby {
+// AddLobby create a lobby with a unique invite code. The lobby can be joined without a password if password is empty.
+func (lobbies *Lobbies) AddLobby(lead *player.Player, settings Settings, password string) (*Lobby, error) {
 	lobbies.Lock()
-	lobby := NewLobby(lobbies.curID, lead, maxNum)
+	defer lobbies.Unlock()
+	code, err := lobbies.newInviteCode()
+	if err != nil {
+		return nil, err
+	}
+	lobby := NewLobby(lobbies.curID, lead, settings, code, password)
 	lobbies.lobbies[lobby.ID] = lobby
+	lobbies.codes[code] = lobby.ID
 	lobbies.curID++
-	lobbies.Unlock()
-	return lobby
+	return lobby, nil
+}
+
+// FindByPlayer return the lobby the player is in. Return nil if the player is not in any lobby.
+func (lobbies *Lobbies) FindByPlayer(id uint32) *Lobby {
+	for _, lobby := range lobbies.Lobbies() {
+		if lobby.HasPlayer(id) {
+			return lobby
+		}
+	}
+	return nil
+}
+
+// FindByCode return the lobby with the invite code. Codes are case-insensitive.
+func (lobbies *Lobbies) FindByCode(code string) (*Lobby, bool) {
+	lobbies.RLock()
+	defer lobbies.RUnlock()
+	id, ok := lobbies.codes[strings.ToUpper(code)]
+	if !ok {
+		return nil, false
+	}
+	return lobbies.lobbies[id], true
+}
+
+func (lobbies *Lobbies) newInviteCode() (string, error) {
+	buf := make([]byte, inviteCodeSize)
+	for {
+		_, err := rand.Read(buf)
+		if err != nil {
+			return "", err
+		}
+		for i, b := range buf {
+			buf[i] = inviteCodeAlphabet[int(b)%len(inviteCodeAlphabet)]
+		}
+		if _, ok := lobbies.codes[string(buf)]; !ok {
+			return string(buf), nil
+		}
+	}
 }
 
+// Lobbies return a copy of all lobbies, so it is safe to iterate while lobbies are added or removed.
 func (lobbies *Lobbies) Lobbies() map[uint32]*Lobby {
 	lobbies.RLock()
 	defer lobbies.RUnlock()
-	return lobbies.lobbies
+	m := make(map[uint32]*Lobby, len(lobbies.lobbies))
+	for k, v := range lobbies.lobbies {
+		m[k] = v
+	}
+	return m
 }
 
 // RmLobby remove the lobby from lobbies. Return true if success, else false.
 func (lobbies *Lobbies) RmLobby(id uint32) bool {
 	lobbies.Lock()
-	_, ok := lobbies.lobbies[id]
+	defer lobbies.Unlock()
+	lobby, ok := lobbies.lobbies[id]
 	if !ok {
 		return false
 	}
+	delete(lobbies.codes, lobby.InviteCode())
 	delete(lobbies.lobbies, id)
-	lobbies.Unlock()
 	return true
 }
-
-func (lobbies *Lobbies) MarshalProtoBuf() (*protos.Lobbies, error) {
-	lobbies.RLock()
-	defer lobbies.RUnlock()
-	m := make(map[uint32]*protos.Lobby)
-	for k, v := range lobbies.lobbies {
-		data, err := v.MarshalProtoBuf()
-		if err != nil {
-			return nil, err
-		}
-		m[k] = data
-	}
-	return &protos.Lobbies{Lobbies: m}, nil
-}
@@ -1,10 +1,13 @@
 package lobby
 
 import (
+	"crypto/subtle"
 	"errors"
 	"github.com/ppodds/hide-and-seek/protos"
+	"github.com/ppodds/hide-and-seek/server/game"
 	"github.com/ppodds/hide-and-seek/server/player"
 	"sync"
+	"time"
 )
 
 type Lobby struct {
@@ -14,15 +17,28 @@ type Lobby struct {
 	curPeople uint32
 	maxPeople uint32
 	inGame    bool
+	settings  Settings
+	// bans is the set of usernames which can't join the lobby
+	bans       map[string]struct{}
+	inviteCode string
+	password   string
+	ready      map[uint32]bool
+	chat       []*ChatMessage
+	match      *game.Match
 	sync.RWMutex
 }
 
-func NewLobby(id uint32, lead *player.Player, maxPeople uint32) *Lobby {
+func NewLobby(id uint32, lead *player.Player, settings Settings, inviteCode string, password string) *Lobby {
 	lobby := new(Lobby)
 	lobby.ID = id
 	lobby.lead = lead
 	lobby.players = []*player.Player{lead}
-	lobby.maxPeople = maxPeople
+	lobby.maxPeople = settings.MaxPlayers
+	lobby.settings = settings
+	lobby.bans = make(map[string]struct{})
+	lobby.inviteCode = inviteCode
+	lobby.password = password
+	lobby.ready = make(map[uint32]bool)
 	lobby.curPeople = 1
 	lobby.inGame = false
 	return lobby
@@ -43,7 +59,48 @@ func (lobby *Lobby) MarshalProtoBuf() (*protos.Lobby, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &protos.Lobby{Id: lobby.ID, Lead: lead, Players: players, CurPeople: lobby.curPeople, MaxPeople: lobby.maxPeople, InGame: lobby.inGame}, nil
+	settings, err := lobby.settings.MarshalProtoBuf()
+	if err != nil {
+		return nil, err
+	}
+	ready := make([]uint32, 0, len(lobby.ready))
+	for _, v := range lobby.players {
+		if lobby.ready[v.ID] {
+			ready = append(ready, v.ID)
+		}
+	}
+	return &protos.Lobby{
+		Id:          lobby.ID,
+		Lead:        lead,
+		Players:     players,
+		CurPeople:   lobby.curPeople,
+		MaxPeople:   lobby.maxPeople,
+		InGame:      lobby.inGame,
+		Settings:    settings,
+		HasPassword: lobby.password != "",
+		InviteCode:  lobby.inviteCode,
+		Ready:       ready,
+	}, nil
+}
+
+// MarshalSummary return the compact form of the lobby for listing.
+func (lobby *Lobby) MarshalSummary() *protos.LobbySummary {
+	lobby.RLock()
+	defer lobby.RUnlock()
+	return &protos.LobbySummary{
+		Id:            lobby.ID,
+		LeadName:      lobby.lead.Name(),
+		CurPeople:     lobby.curPeople,
+		MaxPeople:     lobby.maxPeople,
+		InGame:        lobby.inGame,
+		HasPassword:   lobby.password != "",
+		Map:           lobby.settings.Map,
+		RoundDuration: uint32(lobby.settings.RoundDuration / time.Second),
+		Ghosts:        lobby.settings.Ghosts,
+		CatchRadius:   lobby.settings.CatchRadius,
+		Mode:          lobby.settings.Mode,
+		Rounds:        lobby.settings.Rounds,
+	}
 }
 
 // AddPlayer Add a player into a lobby. Return new lobby if success, else nil.
@@ -72,6 +129,7 @@ func (lobby *Lobby) RmPeople(player *player.Player) (*Lobby, error) {
 		return lobby, errors.New("can't find the player")
 	}
 	lobby.players = append(lobby.players[:pos], lobby.players[pos+1:]...)
+	delete(lobby.ready, player.ID)
 	lobby.curPeople -= 1
 	return lobby, nil
 }
@@ -88,10 +146,114 @@ func (lobby *Lobby) Lead() *player.Player {
 	return lobby.lead
 }
 
+// SetLead make the player the lead. The player must be in the lobby.
+func (lobby *Lobby) SetLead(player *player.Player) error {
+	lobby.Lock()
+	defer lobby.Unlock()
+	for _, p := range lobby.players {
+		if p.ID == player.ID {
+			lobby.lead = p
+			return nil
+		}
+	}
+	return errors.New("can't find the player")
+}
+
+// Players return a copy of the players in join order.
 func (lobby *Lobby) Players() []*player.Player {
 	lobby.RLock()
 	defer lobby.RUnlock()
-	return lobby.players
+	players := make([]*player.Player, len(lobby.players))
+	copy(players, lobby.players)
+	return players
+}
+
+func (lobby *Lobby) HasPlayer(id uint32) bool {
+	lobby.RLock()
+	defer lobby.RUnlock()
+	for _, p := range lobby.players {
+		if p.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Ban forbid the player to join the lobby again. Bans are kept by username, so logging in again doesn't lift them.
+func (lobby *Lobby) Ban(player *player.Player) {
+	lobby.Lock()
+	defer lobby.Unlock()
+	lobby.bans[player.Name()] = struct{}{}
+}
+
+func (lobby *Lobby) IsBanned(player *player.Player) bool {
+	lobby.RLock()
+	defer lobby.RUnlock()
+	_, ok := lobby.bans[player.Name()]
+	return ok
+}
+
+func (lobby *Lobby) InviteCode() string {
+	return lobby.inviteCode
+}
+
+// CheckPassword report whether password can be used to join the lobby. Any password is accepted if the lobby has none.
+func (lobby *Lobby) CheckPassword(password string) bool {
+	if lobby.password == "" {
+		return true
+	}
+	return subtle.ConstantTimeCompare([]byte(lobby.password), []byte(password)) == 1
+}
+
+func (lobby *Lobby) Settings() Settings {
+	lobby.RLock()
+	defer lobby.RUnlock()
+	return lobby.settings
+}
+
+// SetReady mark whether the player is ready to start. The player must be in the lobby.
+func (lobby *Lobby) SetReady(player *player.Player, ready bool) error {
+	lobby.Lock()
+	defer lobby.Unlock()
+	for _, p := range lobby.players {
+		if p.ID == player.ID {
+			lobby.ready[player.ID] = ready
+			return nil
+		}
+	}
+	return errors.New("can't find the player")
+}
+
+// AllReady report whether every player in the lobby is ready.
+func (lobby *Lobby) AllReady() bool {
+	lobby.RLock()
+	defer lobby.RUnlock()
+	for _, p := range lobby.players {
+		if !lobby.ready[p.ID] {
+			return false
+		}
+	}
+	return true
+}
+
+// ResetReady mark every player as not ready, so they have to confirm again before the next game.
+func (lobby *Lobby) ResetReady() {
+	lobby.Lock()
+	defer lobby.Unlock()
+	lobby.ready = make(map[uint32]bool)
+}
+
+// Match return the match being played in the lobby. Return nil if no match is being played.
+func (lobby *Lobby) Match() *game.Match {
+	lobby.RLock()
+	defer lobby.RUnlock()
+	return lobby.match
+}
+
+func (lobby *Lobby) SetMatch(match *game.Match) {
+	lobby.Lock()
+	defer lobby.Unlock()
+	lobby.match = match
 }
 
 func (lobby *Lobby) InGame() bool {
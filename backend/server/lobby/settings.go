@@ -0,0 +1,116 @@
+package lobby
+
+import (
+	"errors"
+	"github.com/ppodds/hide-and-seek/protos"
+	"time"
+)
+
+type Settings struct {
+	MaxPlayers    uint32
+	RoundDuration time.Duration
+	Ghosts        uint32
+	CatchRadius   float32
+	Map           string
+	LeadHandover  bool
+	Private       bool
+	Mode          string
+	Rounds        uint32
+}
+
+// Limits is the range of settings allowed by the server.
+type Limits struct {
+	MaxPlayers       uint32
+	MinRoundDuration time.Duration
+	MaxRoundDuration time.Duration
+	MaxGhosts        uint32
+	MaxCatchRadius   float32
+	Maps             []string
+	Modes            []string
+	MaxRounds        uint32
+}
+
+// NewSettings build the settings requested by the client. Fields which are not set take the value of defaults.
+func NewSettings(req *protos.LobbySettings, defaults Settings, limits Limits) (Settings, error) {
+	settings := defaults
+	if req == nil {
+		return settings, nil
+	}
+	if req.MaxPlayers != 0 {
+		settings.MaxPlayers = req.MaxPlayers
+	}
+	if req.RoundDuration != 0 {
+		settings.RoundDuration = time.Duration(req.RoundDuration) * time.Second
+	}
+	if req.Ghosts != 0 {
+		settings.Ghosts = req.Ghosts
+	}
+	if req.CatchRadius != 0 {
+		settings.CatchRadius = req.CatchRadius
+	}
+	if req.Map != "" {
+		settings.Map = req.Map
+	}
+	settings.LeadHandover = req.LeadHandover
+	settings.Private = req.Private
+	if req.Mode != "" {
+		settings.Mode = req.Mode
+	}
+	if req.Rounds != 0 {
+		settings.Rounds = req.Rounds
+	}
+	err := settings.Validate(limits)
+	if err != nil {
+		return Settings{}, err
+	}
+	return settings, nil
+}
+
+func (settings *Settings) Validate(limits Limits) error {
+	if settings.MaxPlayers < 2 || settings.MaxPlayers > limits.MaxPlayers {
+		return errors.New("max players is out of range")
+	}
+	if settings.RoundDuration < limits.MinRoundDuration || settings.RoundDuration > limits.MaxRoundDuration {
+		return errors.New("round duration is out of range")
+	}
+	if settings.Ghosts < 1 || settings.Ghosts > limits.MaxGhosts || settings.Ghosts >= settings.MaxPlayers {
+		return errors.New("ghost amount is out of range")
+	}
+	// written as a negation, so NaN is rejected too
+	if !(settings.CatchRadius > 0 && settings.CatchRadius <= limits.MaxCatchRadius) {
+		return errors.New("catch radius is out of range")
+	}
+	if settings.Rounds < 1 || settings.Rounds > limits.MaxRounds {
+		return errors.New("rounds is out of range")
+	}
+	if !contains(limits.Modes, settings.Mode) {
+		return errors.New("unknown mode")
+	}
+	if !contains(limits.Maps, settings.Map) {
+		return errors.New("unknown map")
+	}
+	return nil
+}
+
+func (settings *Settings) MarshalProtoBuf() (*protos.LobbySettings, error) {
+	return &protos.LobbySettings{
+		MaxPlayers:    settings.MaxPlayers,
+		RoundDuration: uint32(settings.RoundDuration / time.Second),
+		Ghosts:        settings.Ghosts,
+		CatchRadius:   settings.CatchRadius,
+		Map:           settings.Map,
+		LeadHandover:  settings.LeadHandover,
+		Private:       settings.Private,
+		Mode:          settings.Mode,
+		Rounds:        settings.Rounds,
+	}, nil
+}
+
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package lobby
+
+import (
+	"github.com/ppodds/hide-and-seek/protos"
+	"math"
+	"testing"
+	"time"
+)
+
+var testDefaults = Settings{
+	MaxPlayers:    4,
+	RoundDuration: 3 * time.Minute,
+	Ghosts:        1,
+	CatchRadius:   1,
+	Map:           "default",
+	Mode:          "classic",
+	Rounds:        1,
+}
+
+var testLimits = Limits{
+	MaxPlayers:       8,
+	MinRoundDuration: 30 * time.Second,
+	MaxRoundDuration: 10 * time.Minute,
+	MaxGhosts:        3,
+	MaxCatchRadius:   5,
+	Maps:             []string{"default", "maze"},
+	Modes:            []string{"classic", "infection"},
+	MaxRounds:        10,
+}
+
+func TestNewSettings(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     *protos.LobbySettings
+		want    Settings
+		wantErr bool
+	}{
+		{name: "nil request", req: nil, want: testDefaults},
+		{name: "zero fields take defaults", req: &protos.LobbySettings{}, want: testDefaults},
+		{
+			name: "override",
+			req:  &protos.LobbySettings{MaxPlayers: 6, RoundDuration: 60, Ghosts: 2, CatchRadius: 2.5, Map: "maze", Mode: "infection", Rounds: 3, Private: true},
+			want: Settings{MaxPlayers: 6, RoundDuration: time.Minute, Ghosts: 2, CatchRadius: 2.5, Map: "maze", Mode: "infection", Rounds: 3, Private: true},
+		},
+		{name: "nan catch radius", req: &protos.LobbySettings{CatchRadius: float32(math.NaN())}, wantErr: true},
+		{name: "negative catch radius", req: &protos.LobbySettings{CatchRadius: -1}, wantErr: true},
+		{name: "infinite catch radius", req: &protos.LobbySettings{CatchRadius: float32(math.Inf(1))}, wantErr: true},
+		{name: "catch radius over max", req: &protos.LobbySettings{CatchRadius: 5.5}, wantErr: true},
+		{name: "max players over max", req: &protos.LobbySettings{MaxPlayers: 9}, wantErr: true},
+		{name: "round duration too short", req: &protos.LobbySettings{RoundDuration: 10}, wantErr: true},
+		{name: "ghosts over max", req: &protos.LobbySettings{MaxPlayers: 8, Ghosts: 4}, wantErr: true},
+		{name: "ghosts fill the lobby", req: &protos.LobbySettings{MaxPlayers: 2, Ghosts: 2}, wantErr: true},
+		{name: "rounds over max", req: &protos.LobbySettings{Rounds: 11}, wantErr: true},
+		{name: "unknown map", req: &protos.LobbySettings{Map: "nowhere"}, wantErr: true},
+		{name: "unknown mode", req: &protos.LobbySettings{Mode: "tag"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewSettings(tt.req, testDefaults, testLimits)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(settings *Settings)
+		wantErr bool
+	}{
+		{name: "defaults", modify: func(settings *Settings) {}},
+		{name: "zero max players", modify: func(settings *Settings) { settings.MaxPlayers = 0 }, wantErr: true},
+		{name: "zero round duration", modify: func(settings *Settings) { settings.RoundDuration = 0 }, wantErr: true},
+		{name: "zero ghosts", modify: func(settings *Settings) { settings.Ghosts = 0 }, wantErr: true},
+		{name: "zero catch radius", modify: func(settings *Settings) { settings.CatchRadius = 0 }, wantErr: true},
+		{name: "nan catch radius", modify: func(settings *Settings) { settings.CatchRadius = float32(math.NaN()) }, wantErr: true},
+		{name: "max catch radius", modify: func(settings *Settings) { settings.CatchRadius = 5 }},
+		{name: "zero rounds", modify: func(settings *Settings) { settings.Rounds = 0 }, wantErr: true},
+		{name: "empty map", modify: func(settings *Settings) { settings.Map = "" }, wantErr: true},
+		{name: "empty mode", modify: func(settings *Settings) { settings.Mode = "" }, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := testDefaults
+			tt.modify(&settings)
+			err := settings.Validate(testLimits)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
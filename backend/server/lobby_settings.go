@@ -0,0 +1,41 @@
+package server
+
+import (
+	"sort"
+	"time"
+
+	"github.com/ppodds/hide-and-seek/server/game"
+	"github.com/ppodds/hide-and-seek/server/lobby"
+)
+
+// LobbyDefaults return the settings of a lobby when the lead doesn't choose.
+func (app *App) LobbyDefaults() lobby.Settings {
+	return lobby.Settings{
+		MaxPlayers:    4,
+		RoundDuration: 3 * time.Minute,
+		Ghosts:        1,
+		CatchRadius:   float32(app.Config.CatchRadius),
+		Map:           game.DefaultMap,
+		Mode:          game.ClassicMode,
+		Rounds:        1,
+	}
+}
+
+// LobbyLimits return the range of settings a lead can choose.
+func (app *App) LobbyLimits() lobby.Limits {
+	maps := make([]string, 0, len(app.Maps))
+	for name := range app.Maps {
+		maps = append(maps, name)
+	}
+	sort.Strings(maps)
+	return lobby.Limits{
+		MaxPlayers:       uint32(app.Config.MaxLobbyPlayers),
+		MinRoundDuration: 30 * time.Second,
+		MaxRoundDuration: app.Config.MaxRoundDuration,
+		MaxGhosts:        uint32(app.Config.MaxGhosts),
+		MaxCatchRadius:   float32(app.Config.MaxCatchRadius),
+		Maps:             maps,
+		Modes:            game.ModeNames(),
+		MaxRounds:        uint32(app.Config.MaxRounds),
+	}
+}
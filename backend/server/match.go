@@ -0,0 +1,128 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/ppodds/hide-and-seek/protos"
+	"github.com/ppodds/hide-and-seek/server/game"
+	"github.com/ppodds/hide-and-seek/server/lobby"
+	"github.com/ppodds/hide-and-seek/server/player"
+)
+
+// CreateRound create the game of the next round of the lobby's match with the connected players. The game is not
+// running until RunRound.
+func (app *App) CreateRound(l *lobby.Lobby) (*game.Game, error) {
+	match := l.Match()
+	if match == nil {
+		return nil, errors.New("lobby is not playing a match")
+	}
+	settings := l.Settings()
+	gameMap, ok := app.Maps[settings.Map]
+	if !ok {
+		return nil, errors.New("unknown map")
+	}
+	mode, ok := game.FindMode(settings.Mode)
+	if !ok {
+		return nil, errors.New("unknown mode")
+	}
+	players := roundPlayers(l)
+	if len(players) < 2 {
+		return nil, errors.New("not enough players are connected")
+	}
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	g := app.Games.CreateGame(l.ID, players, game.Settings{
+		TickRate:      app.Config.TickRate,
+		CatchRadius:   settings.CatchRadius,
+		Ghosts:        settings.Ghosts,
+		GhostOrder:    match.GhostOrder(players, r),
+		RoundDuration: settings.RoundDuration,
+		Map:           gameMap,
+		Mode:          mode,
+		MaxSpeed: map[game.CharacterType]float32{
+			game.GHOST:  float32(app.Config.GhostMaxSpeed),
+			game.PLAYER: float32(app.Config.PlayerMaxSpeed),
+		},
+		KickViolations: app.Config.KickViolations,
+	})
+	match.StartRound(g)
+	return g, nil
+}
+
+// roundPlayers return the players of the lobby who can play the next round. Players who lost their session or never
+// connected over udp sit the round out, because they would miss the game broadcast.
+func roundPlayers(l *lobby.Lobby) []*player.Player {
+	players := make([]*player.Player, 0, l.CurPeople())
+	for _, p := range l.Players() {
+		if p.Session() == nil || p.UDPAddr() == nil {
+			fmt.Println("player", p.ID, "sits out the round of lobby", l.ID, "because it is not connected")
+			continue
+		}
+		players = append(players, p)
+	}
+	return players
+}
+
+// RunRound start the game and push START to the lobby. If the game can't start, it is removed and an error is
+// returned, so the caller can reopen the lobby.
+func (app *App) RunRound(l *lobby.Lobby, g *game.Game) error {
+	initGame, err := g.MarshalProtoBuf()
+	if err != nil {
+		app.Games.RmGame(g.ID())
+		return err
+	}
+	g.Run(app.EndGame)
+	err = Push(l.Players(), &protos.LobbyBroadcast{
+		Event:    protos.LobbyEvent_START,
+		InitGame: initGame,
+	})
+	if err != nil {
+		fmt.Println("failed to push round start of lobby", l.ID, "because", err)
+	}
+	return nil
+}
+
+// nextRound start the next round of the lobby's match after the intermission. The match ends early if the lobby is
+// gone or not enough players are left or connected.
+func (app *App) nextRound(l *lobby.Lobby) {
+	if _, ok := app.Lobbies.Lobbies()[l.ID]; !ok {
+		return
+	}
+	if len(roundPlayers(l)) < 2 {
+		app.endMatch(l)
+		return
+	}
+	g, err := app.CreateRound(l)
+	if err != nil {
+		fmt.Println("failed to start round of lobby", l.ID, "because", err)
+		app.endMatch(l)
+		return
+	}
+	err = app.RunRound(l, g)
+	if err != nil {
+		fmt.Println("failed to run round of lobby", l.ID, "because", err)
+		app.endMatch(l)
+	}
+}
+
+// endMatch push the final standings and reopen the lobby.
+func (app *App) endMatch(l *lobby.Lobby) {
+	match := l.Match()
+	l.SetMatch(nil)
+	l.SetInGame(false)
+	l.ResetReady()
+	if match == nil {
+		return
+	}
+	scoreboard, err := match.MarshalProtoBuf()
+	if err != nil {
+		fmt.Println("failed to marshal standings of lobby", l.ID, "because", err)
+		return
+	}
+	err = Push(l.Players(), &protos.LobbyBroadcast{Event: protos.LobbyEvent_MATCH_OVER, Scoreboard: scoreboard})
+	if err != nil {
+		fmt.Println("failed to push standings of lobby", l.ID, "because", err)
+	}
+}
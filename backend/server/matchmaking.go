@@ -0,0 +1,92 @@
+package server
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ppodds/hide-and-seek/protos"
+	"github.com/ppodds/hide-and-seek/server/lobby"
+	"github.com/ppodds/hide-and-seek/server/matchmaking"
+	"github.com/ppodds/hide-and-seek/server/player"
+)
+
+// minMatchPlayers is the least players to form a lobby, one ghost and one player.
+const minMatchPlayers = 2
+
+// runMatchmaking form lobbies from the matchmaking queue. It blocks, so it should be called in a new goroutine.
+func (app *App) runMatchmaking() {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		for _, group := range app.Matchmaker.Match(now, app.Config.MatchTimeout, minMatchPlayers) {
+			err := app.formLobby(group)
+			if err != nil {
+				fmt.Println("failed to form lobby from matchmaking because", err)
+			}
+		}
+	}
+}
+
+// formLobby create a lobby for the tickets and push MATCH_FOUND to the players. The player who queued first is the
+// lead, and the lead is handed over if it leaves, so the lobby doesn't depend on a single player. If the lobby can't be
+// formed, the players are put back in the queue with their wait time.
+func (app *App) formLobby(group []*matchmaking.Ticket) error {
+	app.Membership.Lock()
+	l, players, err := app.addMatchLobby(group)
+	app.Membership.Unlock()
+	if err != nil || l == nil {
+		return err
+	}
+	lobbyProto, err := l.MarshalProtoBuf()
+	if err != nil {
+		return err
+	}
+	return Push(players, &protos.LobbyBroadcast{Event: protos.LobbyEvent_MATCH_FOUND, Lobby: lobbyProto})
+}
+
+// addMatchLobby add the lobby of the tickets which are still available. Return a nil lobby if there are not enough
+// players. It must be called with Membership locked.
+func (app *App) addMatchLobby(group []*matchmaking.Ticket) (*lobby.Lobby, []*player.Player, error) {
+	tickets := make([]*matchmaking.Ticket, 0, len(group))
+	for _, t := range group {
+		// the player may have logged out or joined a lobby by itself since it was matched
+		if _, ok := app.Players.Players()[t.Player.ID]; !ok || app.Lobbies.FindByPlayer(t.Player.ID) != nil {
+			continue
+		}
+		tickets = append(tickets, t)
+	}
+	if len(tickets) < minMatchPlayers {
+		app.requeue(tickets)
+		return nil, nil, nil
+	}
+	players := make([]*player.Player, 0, len(tickets))
+	for _, t := range tickets {
+		players = append(players, t.Player)
+	}
+	settings := tickets[0].Settings
+	settings.LeadHandover = true
+	l, err := app.Lobbies.AddLobby(players[0], settings, "")
+	if err != nil {
+		app.requeue(tickets)
+		return nil, nil, err
+	}
+	for _, p := range players[1:] {
+		_, err = l.AddPlayer(p)
+		if err != nil {
+			// nobody is notified yet, so the lobby can be dropped silently
+			app.Lobbies.RmLobby(l.ID)
+			app.requeue(tickets)
+			return nil, nil, err
+		}
+	}
+	return l, players, nil
+}
+
+func (app *App) requeue(tickets []*matchmaking.Ticket) {
+	for _, t := range tickets {
+		err := app.Matchmaker.Requeue(t)
+		if err != nil {
+			fmt.Println("failed to requeue player", t.Player.ID, "because", err)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package matchmaking
+
+import (
+	"errors"
+	"github.com/ppodds/hide-and-seek/server/lobby"
+	"github.com/ppodds/hide-and-seek/server/player"
+	"sync"
+	"time"
+)
+
+// Ticket is a player waiting for a match.
+type Ticket struct {
+	Player *player.Player
+	// Settings is the lobby the player prefer. Only tickets with the same max players and map are matched together.
+	Settings lobby.Settings
+	QueuedAt time.Time
+}
+
+type ticketKey struct {
+	maxPlayers uint32
+	m          string
+}
+
+func (ticket *Ticket) key() ticketKey {
+	return ticketKey{ticket.Settings.MaxPlayers, ticket.Settings.Map}
+}
+
+// Queue keep tickets in the order they are queued.
+type Queue struct {
+	tickets []*Ticket
+	sync.Mutex
+}
+
+func NewQueue() *Queue {
+	queue := new(Queue)
+	queue.tickets = make([]*Ticket, 0)
+	return queue
+}
+
+func (queue *Queue) Enqueue(p *player.Player, settings lobby.Settings) error {
+	queue.Lock()
+	defer queue.Unlock()
+	for _, t := range queue.tickets {
+		if t.Player.ID == p.ID {
+			return errors.New("player is already in the queue")
+		}
+	}
+	queue.tickets = append(queue.tickets, &Ticket{Player: p, Settings: settings, QueuedAt: time.Now()})
+	return nil
+}
+
+// Requeue put the ticket back in the queue, ordered by the time it was first queued, so the player keep its wait time.
+func (queue *Queue) Requeue(ticket *Ticket) error {
+	queue.Lock()
+	defer queue.Unlock()
+	pos := len(queue.tickets)
+	for i, t := range queue.tickets {
+		if t.Player.ID == ticket.Player.ID {
+			return errors.New("player is already in the queue")
+		}
+		if pos == len(queue.tickets) && t.QueuedAt.After(ticket.QueuedAt) {
+			pos = i
+		}
+	}
+	queue.tickets = append(queue.tickets, nil)
+	copy(queue.tickets[pos+1:], queue.tickets[pos:])
+	queue.tickets[pos] = ticket
+	return nil
+}
+
+// Dequeue remove the ticket of the player. Return true if the player was in the queue.
+func (queue *Queue) Dequeue(id uint32) bool {
+	queue.Lock()
+	defer queue.Unlock()
+	for i, t := range queue.tickets {
+		if t.Player.ID == id {
+			queue.tickets = append(queue.tickets[:i], queue.tickets[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Match remove and return the groups of tickets which can form a lobby, each in queue order. A group is formed as soon
+// as it can fill a lobby, or with at least minPlayers tickets once its oldest ticket waited longer than timeout.
+func (queue *Queue) Match(now time.Time, timeout time.Duration, minPlayers uint32) [][]*Ticket {
+	queue.Lock()
+	defer queue.Unlock()
+	buckets := make(map[ticketKey][]*Ticket)
+	keys := make([]ticketKey, 0)
+	for _, t := range queue.tickets {
+		k := t.key()
+		if _, ok := buckets[k]; !ok {
+			keys = append(keys, k)
+		}
+		buckets[k] = append(buckets[k], t)
+	}
+	groups := make([][]*Ticket, 0)
+	matched := make(map[*Ticket]bool)
+	for _, k := range keys {
+		bucket := buckets[k]
+		for uint32(len(bucket)) >= k.maxPlayers {
+			groups = append(groups, bucket[:k.maxPlayers])
+			bucket = bucket[k.maxPlayers:]
+		}
+		if len(bucket) != 0 && uint32(len(bucket)) >= minPlayers && now.Sub(bucket[0].QueuedAt) > timeout {
+			groups = append(groups, bucket)
+		}
+	}
+	if len(groups) == 0 {
+		return groups
+	}
+	for _, g := range groups {
+		for _, t := range g {
+			matched[t] = true
+		}
+	}
+	rest := make([]*Ticket, 0, len(queue.tickets))
+	for _, t := range queue.tickets {
+		if !matched[t] {
+			rest = append(rest, t)
+		}
+	}
+	queue.tickets = rest
+	return groups
+}
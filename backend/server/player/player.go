@@ -1,30 +1,84 @@
 package player
 
 import (
+	"crypto/subtle"
 	"github.com/ppodds/hide-and-seek/protos"
+	"github.com/ppodds/hide-and-seek/server/rpc"
 	"net"
 	"sync"
+	"time"
 )
 
 type Player struct {
 	ID      uint32
-	tcpConn *net.TCPConn
+	name    string
+	token   string
+	session *rpc.Session
 	udpConn *net.UDPConn
 	udpAddr *net.UDPAddr
+	// udpSeen is the time of the last authenticated udp call
+	udpSeen time.Time
+	// detachedAt is the time the session was lost. It is zero while the player has a session.
+	detachedAt time.Time
+	// chatAt is the time from which the player can send chat messages again without using its burst
+	chatAt time.Time
 	sync.RWMutex
 }
 
-func NewPlayer(id uint32, tcpConn *net.TCPConn) *Player {
+func NewPlayer(id uint32, name string, token string, session *rpc.Session) *Player {
 	player := new(Player)
 	player.ID = id
-	player.tcpConn = tcpConn
+	player.name = name
+	player.token = token
+	player.session = session
 	return player
 }
 
-func (player *Player) TCPConn() *net.TCPConn {
+// Name return the display name of the player, which is also the username of the account.
+func (player *Player) Name() string {
+	return player.name
+}
+
+// Token return the secret which the client must send with every request on behalf of the player.
+func (player *Player) Token() string {
+	return player.token
+}
+
+// Authenticate report whether token is the session token of the player.
+func (player *Player) Authenticate(token string) bool {
+	return subtle.ConstantTimeCompare([]byte(player.token), []byte(token)) == 1
+}
+
+// Session return the tcp session of the player. Return nil if the player is detached.
+func (player *Player) Session() *rpc.Session {
+	player.RLock()
+	defer player.RUnlock()
+	return player.session
+}
+
+// Detach drop the lost session of the player, keeping the player until it resume or the grace period is over.
+func (player *Player) Detach() {
+	player.Lock()
+	defer player.Unlock()
+	player.session = nil
+	player.detachedAt = time.Now()
+}
+
+// Attach bind a new session to the player. Return the previous session, which may be nil.
+func (player *Player) Attach(session *rpc.Session) *rpc.Session {
+	player.Lock()
+	defer player.Unlock()
+	old := player.session
+	player.session = session
+	player.detachedAt = time.Time{}
+	return old
+}
+
+// DetachedAt return the time the player lost its session. It is zero if the player has a session.
+func (player *Player) DetachedAt() time.Time {
 	player.RLock()
 	defer player.RUnlock()
-	return player.tcpConn
+	return player.detachedAt
 }
 
 func (player *Player) UDPConn() *net.UDPConn {
@@ -51,8 +105,38 @@ func (player *Player) SetUDPAddr(addr *net.UDPAddr) {
 	player.udpAddr = addr
 }
 
+func (player *Player) TouchUDP() {
+	player.Lock()
+	defer player.Unlock()
+	player.udpSeen = time.Now()
+}
+
+// UDPSeen return the time of the last authenticated udp call. It is zero if the player never sent one.
+func (player *Player) UDPSeen() time.Time {
+	player.RLock()
+	defer player.RUnlock()
+	return player.udpSeen
+}
+
+// AllowChat report whether the player can send a chat message now. The player can send one message per interval on
+// average, and up to burst messages at once.
+func (player *Player) AllowChat(interval time.Duration, burst uint) bool {
+	player.Lock()
+	defer player.Unlock()
+	now := time.Now()
+	at := player.chatAt
+	if at.Before(now) {
+		at = now
+	}
+	if burst == 0 || at.Sub(now) > interval*time.Duration(burst-1) {
+		return false
+	}
+	player.chatAt = at.Add(interval)
+	return true
+}
+
 func (player *Player) MarshalProtoBuf() (*protos.Player, error) {
 	player.RLock()
 	defer player.RUnlock()
-	return &protos.Player{Id: player.ID}, nil
+	return &protos.Player{Id: player.ID, Name: player.name}, nil
 }
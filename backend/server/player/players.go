@@ -1,11 +1,15 @@
 package player
 
 import (
-	"github.com/ppodds/hide-and-seek/protos"
-	"net"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"github.com/ppodds/hide-and-seek/server/rpc"
 	"sync"
 )
 
+const tokenSize = 32
+
 type Players struct {
 	sync.RWMutex
 	curID   uint32
@@ -19,23 +23,59 @@ func NewPlayers() *Players {
 	return players
 }
 
-func (players *Players) AddPlayer(tcpConn *net.TCPConn) *Player {
+// AddPlayer log in the account with name. An account can only be logged in once at the same time.
+func (players *Players) AddPlayer(session *rpc.Session, name string) (*Player, error) {
+	token, err := newToken()
+	if err != nil {
+		return nil, err
+	}
 	players.Lock()
-	player := NewPlayer(players.curID, tcpConn)
+	defer players.Unlock()
+	for _, p := range players.players {
+		if p.Name() == name {
+			return nil, errors.New("account is already logged in")
+		}
+	}
+	player := NewPlayer(players.curID, name, token, session)
 	players.players[player.ID] = player
 	players.curID++
-	players.Unlock()
-	return player
+	return player, nil
 }
 
-func (players *Players) RmPlayer(player *protos.Player) {
+func (players *Players) RmPlayer(id uint32) {
 	players.Lock()
 	defer players.Unlock()
-	delete(players.players, player.Id)
+	delete(players.players, id)
+}
+
+// FindBySession return the player logged in with the session. Return false if there is no such player.
+func (players *Players) FindBySession(session *rpc.Session) (*Player, bool) {
+	players.RLock()
+	defer players.RUnlock()
+	for _, player := range players.players {
+		if player.Session() == session {
+			return player, true
+		}
+	}
+	return nil, false
 }
 
+// Players return a copy of all players, so it is safe to iterate while players log in or out.
 func (players *Players) Players() map[uint32]*Player {
 	players.RLock()
 	defer players.RUnlock()
-	return players.players
+	m := make(map[uint32]*Player, len(players.players))
+	for k, v := range players.players {
+		m[k] = v
+	}
+	return m
+}
+
+func newToken() (string, error) {
+	buf := make([]byte, tokenSize)
+	_, err := rand.Read(buf)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
 }
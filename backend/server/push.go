@@ -0,0 +1,29 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/ppodds/hide-and-seek/server/player"
+	"google.golang.org/protobuf/proto"
+)
+
+// Push send msg to the tcp session of every player. Players which can't be reached are skipped.
+func Push(players []*player.Player, msg proto.Message) error {
+	buf, err := proto.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	for _, p := range players {
+		session := p.Session()
+		if session == nil {
+			fmt.Println("skip push to detached player", p.ID)
+			continue
+		}
+		err = session.Push(buf)
+		if err != nil {
+			fmt.Println("skip push to player", p.ID, "because", err)
+			continue
+		}
+	}
+	return nil
+}
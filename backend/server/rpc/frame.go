@@ -0,0 +1,155 @@
+package rpc
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+)
+
+const (
+	CallHeaderSize        = 5
+	SessionCallHeaderSize = 9
+	SessionResHeaderSize  = 9
+	DefaultMaxFrameSize   = 64 * 1024
+)
+
+// FrameType tell the client how to handle a frame sent by the server.
+type FrameType uint8
+
+const (
+	// RES is the response of a call. The request id of the frame is the one of the call.
+	RES FrameType = iota
+	// PUSH is an event initiated by the server. The request id of the frame is always 0.
+	PUSH
+)
+
+var ErrFrameTooLarge = errors.New("frame is too large")
+
+// FrameError describe which step of reading or writing a frame failed.
+type FrameError struct {
+	Op  string
+	Err error
+}
+
+func (e *FrameError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Op, e.Err.Error())
+}
+
+func (e *FrameError) Unwrap() error {
+	return e.Err
+}
+
+// Timeout report whether the frame failed because the deadline exceeded.
+func (e *FrameError) Timeout() bool {
+	var netErr net.Error
+	return errors.As(e.Err, &netErr) && netErr.Timeout()
+}
+
+// FrameReader read length framed calls from a tcp connection.
+type FrameReader struct {
+	conn         net.Conn
+	maxFrameSize uint32
+	timeout      time.Duration
+	idleTimeout  time.Duration
+}
+
+// NewFrameReader create a reader. timeout limit the time to receive a frame once it begins,
+// idleTimeout limit the time to wait for the next frame. Zero means no limit.
+func NewFrameReader(conn net.Conn, maxFrameSize uint32, timeout time.Duration, idleTimeout time.Duration) *FrameReader {
+	reader := new(FrameReader)
+	reader.conn = conn
+	reader.maxFrameSize = maxFrameSize
+	reader.timeout = timeout
+	reader.idleTimeout = idleTimeout
+	return reader
+}
+
+func (reader *FrameReader) setDeadline(timeout time.Duration) error {
+	deadline := time.Time{}
+	if timeout != 0 {
+		deadline = time.Now().Add(timeout)
+	}
+	err := reader.conn.SetReadDeadline(deadline)
+	if err != nil {
+		return &FrameError{"set read deadline", err}
+	}
+	return nil
+}
+
+// ReadCall read a whole call from the connection. The returned data is nil if the call has no content.
+func (reader *FrameReader) ReadCall() (*RPCContext, []byte, error) {
+	err := reader.setDeadline(reader.idleTimeout)
+	if err != nil {
+		return nil, nil, err
+	}
+	header := make([]byte, SessionCallHeaderSize)
+	_, err = io.ReadFull(reader.conn, header[:1])
+	if err != nil {
+		return nil, nil, &FrameError{"read header", err}
+	}
+	err = reader.setDeadline(reader.timeout)
+	if err != nil {
+		return nil, nil, err
+	}
+	_, err = io.ReadFull(reader.conn, header[1:])
+	if err != nil {
+		return nil, nil, &FrameError{"read header", err}
+	}
+	ctx, err := ParseSessionCall(header)
+	if err != nil {
+		return nil, nil, &FrameError{"parse header", err}
+	}
+	if ctx.ContentLength > reader.maxFrameSize {
+		return nil, nil, &FrameError{"read body", ErrFrameTooLarge}
+	}
+	if ctx.ContentLength == 0 {
+		return ctx, nil, nil
+	}
+	data := make([]byte, ctx.ContentLength)
+	_, err = io.ReadFull(reader.conn, data)
+	if err != nil {
+		return nil, nil, &FrameError{"read body", err}
+	}
+	return ctx, data, nil
+}
+
+// FrameWriter write length framed responses to a tcp connection.
+type FrameWriter struct {
+	conn         net.Conn
+	maxFrameSize uint32
+	timeout      time.Duration
+}
+
+func NewFrameWriter(conn net.Conn, maxFrameSize uint32, timeout time.Duration) *FrameWriter {
+	writer := new(FrameWriter)
+	writer.conn = conn
+	writer.maxFrameSize = maxFrameSize
+	writer.timeout = timeout
+	return writer
+}
+
+func (writer *FrameWriter) WriteFrame(frameType FrameType, requestID uint32, buf []byte) error {
+	if uint64(len(buf)) > uint64(writer.maxFrameSize) {
+		return &FrameError{"write", ErrFrameTooLarge}
+	}
+	if writer.timeout != 0 {
+		err := writer.conn.SetWriteDeadline(time.Now().Add(writer.timeout))
+		if err != nil {
+			return &FrameError{"set write deadline", err}
+		}
+	}
+	fmt.Println("send tcp frame: ", frameType, buf)
+	frame := make([]byte, SessionResHeaderSize+len(buf))
+	frame[0] = byte(frameType)
+	binary.LittleEndian.PutUint32(frame[1:5], requestID)
+	binary.LittleEndian.PutUint32(frame[5:9], uint32(len(buf)))
+	copy(frame[SessionResHeaderSize:], buf)
+	_, err := writer.conn.Write(frame)
+	if err != nil {
+		return &FrameError{"write", err}
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package rpc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+func callFrame(procID uint8, requestID uint32, length uint32, body []byte) []byte {
+	frame := make([]byte, SessionCallHeaderSize, SessionCallHeaderSize+len(body))
+	frame[0] = procID
+	binary.LittleEndian.PutUint32(frame[1:5], requestID)
+	binary.LittleEndian.PutUint32(frame[5:9], length)
+	return append(frame, body...)
+}
+
+// writeFrame write the frame to conn in a new goroutine, then close conn if closeConn is true. The returned channel is
+// closed when the goroutine ends.
+func writeFrame(conn net.Conn, frame []byte, closeConn bool) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if len(frame) != 0 {
+			_, _ = conn.Write(frame)
+		}
+		if closeConn {
+			_ = conn.Close()
+		}
+	}()
+	return done
+}
+
+func TestFrameReaderReadCall(t *testing.T) {
+	tests := []struct {
+		name      string
+		frame     []byte
+		close     bool
+		wantData  []byte
+		wantErr   error
+		wantOp    string
+		wantProc  uint8
+		wantReqID uint32
+	}{
+		{name: "call", frame: callFrame(3, 42, 4, []byte("ping")), wantData: []byte("ping"), wantProc: 3, wantReqID: 42},
+		{name: "empty content", frame: callFrame(7, 1, 0, nil), wantData: nil, wantProc: 7, wantReqID: 1},
+		{name: "max frame size", frame: callFrame(0, 1, 16, make([]byte, 16)), wantData: make([]byte, 16), wantReqID: 1},
+		{name: "too large", frame: callFrame(0, 1, 17, nil), wantErr: ErrFrameTooLarge, wantOp: "read body"},
+		{name: "truncated header", frame: []byte{0, 1, 0}, close: true, wantErr: io.ErrUnexpectedEOF, wantOp: "read header"},
+		{name: "truncated body", frame: callFrame(0, 1, 4, []byte("pi")), close: true, wantErr: io.ErrUnexpectedEOF, wantOp: "read body"},
+		{name: "closed", frame: nil, close: true, wantErr: io.EOF, wantOp: "read header"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			server, client := net.Pipe()
+			done := writeFrame(client, tt.frame, tt.close)
+			reader := NewFrameReader(server, 16, time.Second, time.Second)
+			ctx, data, err := reader.ReadCall()
+			// closing both ends unblock the writer if the reader stopped early
+			_ = server.Close()
+			_ = client.Close()
+			<-done
+			if tt.wantErr != nil {
+				var frameErr *FrameError
+				if !errors.As(err, &frameErr) || !errors.Is(err, tt.wantErr) || frameErr.Op != tt.wantOp {
+					t.Fatalf("error = %v, want %s: %v", err, tt.wantOp, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ctx.ProcID != tt.wantProc || ctx.RequestID != tt.wantReqID {
+				t.Fatalf("got proc %d request %d, want proc %d request %d", ctx.ProcID, ctx.RequestID, tt.wantProc, tt.wantReqID)
+			}
+			if !bytes.Equal(data, tt.wantData) || (data == nil) != (tt.wantData == nil) {
+				t.Fatalf("data = %v, want %v", data, tt.wantData)
+			}
+		})
+	}
+}
+
+func TestFrameReaderTimeout(t *testing.T) {
+	tests := []struct {
+		name        string
+		frame       []byte
+		timeout     time.Duration
+		idleTimeout time.Duration
+	}{
+		{name: "idle", frame: nil, timeout: time.Second, idleTimeout: 20 * time.Millisecond},
+		{name: "partial frame", frame: []byte{0, 1}, timeout: 20 * time.Millisecond, idleTimeout: time.Second},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			server, client := net.Pipe()
+			done := writeFrame(client, tt.frame, false)
+			reader := NewFrameReader(server, 16, tt.timeout, tt.idleTimeout)
+			_, _, err := reader.ReadCall()
+			_ = server.Close()
+			_ = client.Close()
+			<-done
+			var frameErr *FrameError
+			if !errors.As(err, &frameErr) || !frameErr.Timeout() {
+				t.Fatalf("error = %v, want timeout", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package rpc
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// UDPChannel is the first byte of every udp datagram sent by the server.
+type UDPChannel uint8
+
+const (
+	// UNRELIABLE datagrams may be lost or reordered.
+	UNRELIABLE UDPChannel = iota
+	// RELIABLE datagrams carry a sequence number after the channel byte. The client must ack them and deliver them in
+	// sequence order.
+	RELIABLE
+)
+
+const (
+	reliableHeaderSize     = 5
+	reliableInitialBackoff = 100 * time.Millisecond
+	reliableMaxBackoff     = 2 * time.Second
+	reliableMaxAttempts    = 10
+	reliableCheckInterval  = 50 * time.Millisecond
+)
+
+type pendingDatagram struct {
+	conn     *net.UDPConn
+	data     []byte
+	attempts int
+	backoff  time.Duration
+	retryAt  time.Time
+}
+
+type reliablePeer struct {
+	addr    *net.UDPAddr
+	nextSeq uint32
+	pending map[uint32]*pendingDatagram
+	// released peers are removed once every datagram is acked
+	released bool
+}
+
+// ReliableUDP resend datagrams to each peer until they are acked, with exponential backoff.
+type ReliableUDP struct {
+	peers map[string]*reliablePeer
+	// OnGiveUp is called when a datagram to addr is not acked after the max attempts. The pending datagrams of the
+	// peer are dropped but its sequence number is kept, so the client must handshake again to reset its sequence.
+	OnGiveUp func(addr *net.UDPAddr)
+	sync.Mutex
+}
+
+func NewReliableUDP() *ReliableUDP {
+	reliable := new(ReliableUDP)
+	reliable.peers = make(map[string]*reliablePeer)
+	return reliable
+}
+
+// Send send buf to addr with the next sequence number of the peer. The datagram is resent until Ack is called with
+// its sequence number.
+func (reliable *ReliableUDP) Send(conn *net.UDPConn, addr *net.UDPAddr, buf []byte) error {
+	reliable.Lock()
+	peer, ok := reliable.peers[addr.String()]
+	if !ok {
+		peer = &reliablePeer{addr: addr, nextSeq: 1, pending: make(map[uint32]*pendingDatagram)}
+		reliable.peers[addr.String()] = peer
+	}
+	peer.released = false
+	seq := peer.nextSeq
+	peer.nextSeq++
+	data := make([]byte, reliableHeaderSize+len(buf))
+	data[0] = byte(RELIABLE)
+	binary.LittleEndian.PutUint32(data[1:5], seq)
+	copy(data[reliableHeaderSize:], buf)
+	peer.pending[seq] = &pendingDatagram{
+		conn:    conn,
+		data:    data,
+		backoff: reliableInitialBackoff,
+		retryAt: time.Now().Add(reliableInitialBackoff),
+	}
+	reliable.Unlock()
+
+	fmt.Println("send reliable udp response:", seq, buf, "to", addr)
+	_, err := conn.WriteToUDP(data, addr)
+	return err
+}
+
+// Ack mark every datagram sent to addr with sequence number up to seq as received.
+func (reliable *ReliableUDP) Ack(addr *net.UDPAddr, seq uint32) {
+	reliable.Lock()
+	defer reliable.Unlock()
+	peer, ok := reliable.peers[addr.String()]
+	if !ok {
+		return
+	}
+	for s := range peer.pending {
+		if s <= seq {
+			delete(peer.pending, s)
+		}
+	}
+	if peer.released && len(peer.pending) == 0 {
+		delete(reliable.peers, addr.String())
+	}
+}
+
+// RmPeer drop the state of addr, including datagrams which are not acked yet. The next datagram to addr has sequence
+// number 1.
+func (reliable *ReliableUDP) RmPeer(addr *net.UDPAddr) {
+	reliable.Lock()
+	defer reliable.Unlock()
+	delete(reliable.peers, addr.String())
+}
+
+// Release drop the state of addr once every datagram sent to it is acked or given up, unless more datagrams are sent
+// in the meantime.
+func (reliable *ReliableUDP) Release(addr *net.UDPAddr) {
+	reliable.Lock()
+	defer reliable.Unlock()
+	peer, ok := reliable.peers[addr.String()]
+	if !ok {
+		return
+	}
+	if len(peer.pending) == 0 {
+		delete(reliable.peers, addr.String())
+		return
+	}
+	peer.released = true
+}
+
+// Run resend datagrams which are not acked in time. It blocks, so it should be called in a new goroutine.
+func (reliable *ReliableUDP) Run() {
+	ticker := time.NewTicker(reliableCheckInterval)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		reliable.resend(now)
+	}
+}
+
+func (reliable *ReliableUDP) resend(now time.Time) {
+	unreachable := reliable.resendPending(now)
+	if reliable.OnGiveUp == nil {
+		return
+	}
+	for _, addr := range unreachable {
+		reliable.OnGiveUp(addr)
+	}
+}
+
+// resendPending resend the datagrams which are due. Return the peers which are given up.
+func (reliable *ReliableUDP) resendPending(now time.Time) []*net.UDPAddr {
+	reliable.Lock()
+	defer reliable.Unlock()
+	unreachable := make([]*net.UDPAddr, 0)
+	for key, peer := range reliable.peers {
+		for seq, datagram := range peer.pending {
+			if now.Before(datagram.retryAt) {
+				continue
+			}
+			// later datagrams can't be delivered in order without this one, so the peer is treated as unreachable.
+			// The sequence number is kept, so the client never mistake new datagrams for duplicates.
+			if datagram.attempts == reliableMaxAttempts {
+				fmt.Println("give up reliable udp response", seq, "to", peer.addr)
+				peer.pending = make(map[uint32]*pendingDatagram)
+				if peer.released {
+					delete(reliable.peers, key)
+				}
+				unreachable = append(unreachable, peer.addr)
+				break
+			}
+			datagram.attempts++
+			datagram.backoff *= 2
+			if datagram.backoff > reliableMaxBackoff {
+				datagram.backoff = reliableMaxBackoff
+			}
+			datagram.retryAt = now.Add(datagram.backoff)
+			_, err := datagram.conn.WriteToUDP(datagram.data, peer.addr)
+			if err != nil {
+				fmt.Println("failed to resend reliable udp response", seq, "to", peer.addr, "because", err)
+			}
+		}
+	}
+	return unreachable
+}
@@ -0,0 +1,145 @@
+package rpc
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+)
+
+func newUDPPair(t *testing.T) (*net.UDPConn, *net.UDPConn) {
+	t.Helper()
+	server, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = server.Close()
+		_ = client.Close()
+	})
+	return server, client
+}
+
+func recvSeq(t *testing.T, client *net.UDPConn) uint32 {
+	t.Helper()
+	buf := make([]byte, 64)
+	_ = client.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err := client.ReadFromUDP(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n < reliableHeaderSize || UDPChannel(buf[0]) != RELIABLE {
+		t.Fatalf("unexpected datagram %v", buf[:n])
+	}
+	return binary.LittleEndian.Uint32(buf[1:5])
+}
+
+func pendingCount(reliable *ReliableUDP, addr *net.UDPAddr) int {
+	reliable.Lock()
+	defer reliable.Unlock()
+	peer, ok := reliable.peers[addr.String()]
+	if !ok {
+		return -1
+	}
+	return len(peer.pending)
+}
+
+func TestReliableUDPSeq(t *testing.T) {
+	server, client := newUDPPair(t)
+	addr := client.LocalAddr().(*net.UDPAddr)
+	reliable := NewReliableUDP()
+	for want := uint32(1); want <= 3; want++ {
+		if err := reliable.Send(server, addr, []byte{byte(want)}); err != nil {
+			t.Fatal(err)
+		}
+		if got := recvSeq(t, client); got != want {
+			t.Fatalf("seq = %d, want %d", got, want)
+		}
+	}
+
+	reliable.RmPeer(addr)
+	if err := reliable.Send(server, addr, []byte{0}); err != nil {
+		t.Fatal(err)
+	}
+	if got := recvSeq(t, client); got != 1 {
+		t.Fatalf("seq after RmPeer = %d, want 1", got)
+	}
+}
+
+func TestReliableUDPAck(t *testing.T) {
+	addr := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 1}
+	tests := []struct {
+		name    string
+		ack     uint32
+		pending int
+	}{
+		{"none", 0, 3},
+		{"cumulative", 2, 1},
+		{"all", 3, 0},
+		{"beyond", 10, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, _ := newUDPPair(t)
+			reliable := NewReliableUDP()
+			for i := 0; i < 3; i++ {
+				_ = reliable.Send(server, addr, []byte{0})
+			}
+			reliable.Ack(addr, tt.ack)
+			if got := pendingCount(reliable, addr); got != tt.pending {
+				t.Fatalf("pending = %d, want %d", got, tt.pending)
+			}
+		})
+	}
+}
+
+func TestReliableUDPRelease(t *testing.T) {
+	server, _ := newUDPPair(t)
+	addr := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 1}
+	reliable := NewReliableUDP()
+	_ = reliable.Send(server, addr, []byte{0})
+	reliable.Release(addr)
+	if got := pendingCount(reliable, addr); got != 1 {
+		t.Fatalf("released peer dropped before ack, pending = %d", got)
+	}
+	reliable.Ack(addr, 1)
+	if got := pendingCount(reliable, addr); got != -1 {
+		t.Fatalf("released peer kept after ack, pending = %d", got)
+	}
+}
+
+func TestReliableUDPGiveUp(t *testing.T) {
+	server, _ := newUDPPair(t)
+	addr := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 1}
+	reliable := NewReliableUDP()
+	givenUp := make([]*net.UDPAddr, 0)
+	reliable.OnGiveUp = func(addr *net.UDPAddr) {
+		givenUp = append(givenUp, addr)
+	}
+	_ = reliable.Send(server, addr, []byte{0})
+	_ = reliable.Send(server, addr, []byte{0})
+
+	now := time.Now()
+	for i := 0; i <= reliableMaxAttempts; i++ {
+		now = now.Add(reliableMaxBackoff)
+		reliable.resend(now)
+	}
+	if len(givenUp) != 1 || givenUp[0].String() != addr.String() {
+		t.Fatalf("given up = %v, want [%v]", givenUp, addr)
+	}
+	if got := pendingCount(reliable, addr); got != 0 {
+		t.Fatalf("pending after give up = %d, want 0", got)
+	}
+
+	// the client already delivered some datagrams, so the sequence must not restart
+	reliable.Lock()
+	nextSeq := reliable.peers[addr.String()].nextSeq
+	reliable.Unlock()
+	if nextSeq != 3 {
+		t.Fatalf("next seq after give up = %d, want 3", nextSeq)
+	}
+}
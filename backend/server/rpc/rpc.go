@@ -9,11 +9,12 @@ import (
 
 type RPCContext struct {
 	ProcID        uint8
+	RequestID     uint32
 	ContentLength uint32
 }
 
 func ParseCall(buf []byte) (*RPCContext, error) {
-	if len(buf) != 5 {
+	if len(buf) != CallHeaderSize {
 		return nil, errors.New("wrong size buffer")
 	}
 	ctx := new(RPCContext)
@@ -22,25 +23,25 @@ func ParseCall(buf []byte) (*RPCContext, error) {
 	return ctx, nil
 }
 
-func SendTCPRes(conn *net.TCPConn, buf []byte) error {
-	header := make([]byte, 4)
-	binary.LittleEndian.PutUint32(header, uint32(len(buf)))
-	fmt.Println("send tcp response header: ", header)
-	_, err := conn.Write(header)
-	if err != nil {
-		return err
-	}
-	fmt.Println("send tcp response: ", buf)
-	_, err = conn.Write(buf)
-	if err != nil {
-		return err
+// ParseSessionCall parse the header of a call sent over a tcp session, which carry a request id.
+func ParseSessionCall(buf []byte) (*RPCContext, error) {
+	if len(buf) != SessionCallHeaderSize {
+		return nil, errors.New("wrong size buffer")
 	}
-	return nil
+	ctx := new(RPCContext)
+	ctx.ProcID = buf[0]
+	ctx.RequestID = binary.LittleEndian.Uint32(buf[1:5])
+	ctx.ContentLength = binary.LittleEndian.Uint32(buf[5:9])
+	return ctx, nil
 }
 
+// SendUDPRes send buf to addr through the unreliable channel.
 func SendUDPRes(conn *net.UDPConn, addr *net.UDPAddr, buf []byte) error {
 	fmt.Println("send udp response:", buf, "to", addr)
-	_, err := conn.WriteToUDP(buf, addr)
+	data := make([]byte, 1+len(buf))
+	data[0] = byte(UNRELIABLE)
+	copy(data[1:], buf)
+	_, err := conn.WriteToUDP(data, addr)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,76 @@
+package rpc
+
+import (
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Session is a long-lived tcp connection which carry many calls in sequence.
+type Session struct {
+	conn   *net.TCPConn
+	reader *FrameReader
+	writer *FrameWriter
+	closed bool
+	// lastSeen is the unix nano time of the last call, which is read by other goroutines to detect dead sessions
+	lastSeen int64
+	sync.Mutex
+}
+
+func NewSession(conn *net.TCPConn, maxFrameSize uint32, timeout time.Duration, idleTimeout time.Duration) *Session {
+	session := new(Session)
+	session.conn = conn
+	session.reader = NewFrameReader(conn, maxFrameSize, timeout, idleTimeout)
+	session.writer = NewFrameWriter(conn, maxFrameSize, timeout)
+	session.lastSeen = time.Now().UnixNano()
+	return session
+}
+
+func (session *Session) RemoteAddr() net.Addr {
+	return session.conn.RemoteAddr()
+}
+
+// ReadCall read the next call of the session. It should only be called by the session loop.
+func (session *Session) ReadCall() (*RPCContext, []byte, error) {
+	ctx, data, err := session.reader.ReadCall()
+	if err == nil {
+		atomic.StoreInt64(&session.lastSeen, time.Now().UnixNano())
+	}
+	return ctx, data, err
+}
+
+// LastSeen return the time the last call was received.
+func (session *Session) LastSeen() time.Time {
+	return time.Unix(0, atomic.LoadInt64(&session.lastSeen))
+}
+
+// WriteRes send the response of the call with requestID. It is safe to call concurrently.
+func (session *Session) WriteRes(requestID uint32, buf []byte) error {
+	session.Lock()
+	defer session.Unlock()
+	if session.closed {
+		return net.ErrClosed
+	}
+	return session.writer.WriteFrame(RES, requestID, buf)
+}
+
+// Push send a server initiated event to the client. It is safe to call concurrently.
+func (session *Session) Push(buf []byte) error {
+	session.Lock()
+	defer session.Unlock()
+	if session.closed {
+		return net.ErrClosed
+	}
+	return session.writer.WriteFrame(PUSH, 0, buf)
+}
+
+func (session *Session) Close() error {
+	session.Lock()
+	defer session.Unlock()
+	if session.closed {
+		return nil
+	}
+	session.closed = true
+	return session.conn.Close()
+}
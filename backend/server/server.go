@@ -1,15 +1,19 @@
 package server
 
 import (
-	"flag"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"math"
 	"net"
 	"os"
 	"sync"
 
+	"github.com/ppodds/hide-and-seek/server/account"
 	"github.com/ppodds/hide-and-seek/server/game"
 	"github.com/ppodds/hide-and-seek/server/lobby"
+	"github.com/ppodds/hide-and-seek/server/matchmaking"
 	"github.com/ppodds/hide-and-seek/server/player"
 	"github.com/ppodds/hide-and-seek/server/rpc"
 )
@@ -23,6 +27,14 @@ type App struct {
 	Lobbies    *lobby.Lobbies
 	Players    *player.Players
 	Games      *game.Games
+	Reliable   *rpc.ReliableUDP
+	Accounts   account.Store
+	Matchmaker *matchmaking.Queue
+	// Membership serialize adding players to lobbies and removing them from the matchmaking queue, so a matched player
+	// isn't put in a lobby formed by matchmaking while it joins another lobby.
+	Membership sync.Mutex
+	Maps       map[string]*game.Map
+	Config     *Config
 }
 
 func NewApp() *App {
@@ -30,65 +42,88 @@ func NewApp() *App {
 	app.udpProcNum = 0
 	app.Lobbies = lobby.NewLobbys()
 	app.Players = player.NewPlayers()
-	app.Games = game.NewGames()
+	app.Reliable = rpc.NewReliableUDP()
+	app.Reliable.OnGiveUp = app.dropUnreachable
+	app.Games = game.NewGames(app.Reliable)
+	app.Matchmaker = matchmaking.NewQueue()
+	app.Config = DefaultConfig()
 	return app
 }
 
+// HandleTcpProc run the session loop of a tcp connection until the client disconnect.
 func (app *App) HandleTcpProc(conn *net.TCPConn) {
-	buf := make([]byte, 5)
-	_, err := conn.Read(buf)
-	if err != nil {
-		fmt.Println(err)
-		return
-	}
-	ctx, err := rpc.ParseCall(buf)
-	if err != nil {
-		fmt.Printf("unsupport protocal. error: %s\n", err.Error())
-		return
-	}
-	if ctx.ContentLength != 0 {
-		buf = make([]byte, ctx.ContentLength)
-		_, err := conn.Read(buf)
+	session := rpc.NewSession(conn, uint32(app.Config.MaxFrameSize), app.Config.IOTimeout, app.Config.IdleTimeout)
+	defer app.closeSession(session)
+
+	for {
+		ctx, data, err := session.ReadCall()
 		if err != nil {
-			fmt.Println(err)
+			if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				fmt.Printf("failed to read tcp call from %s. error: %s\n", session.RemoteAddr(), err.Error())
+			}
 			return
 		}
-	} else {
-		buf = nil
+		if !(ctx.ProcID < app.tcpProcNum) {
+			fmt.Println("skip unknown TCP Proc", ctx.ProcID)
+			continue
+		}
+		tcpCtx := TCPContext{app, session, ctx.RequestID, data}
+		fmt.Println("Invoke TCP Proc", ctx.ProcID)
+		err = app.tcpProcs[ctx.ProcID].Proc(&tcpCtx)
+		if err != nil {
+			err := app.tcpProcs[ctx.ProcID].ErrorHandler(err, &tcpCtx)
+			if err != nil {
+				fmt.Println(err)
+			}
+		}
+	}
+}
+
+// closeSession close the connection and log out the player bound to the session. A player in a game is kept for the
+// grace period, so it can resume with a new session.
+func (app *App) closeSession(session *rpc.Session) {
+	err := session.Close()
+	if err != nil {
+		fmt.Println("Error closing session:", err)
 	}
-	if !(ctx.ProcID < app.tcpProcNum) {
+	player, ok := app.Players.FindBySession(session)
+	if !ok {
 		return
 	}
-	tcpCtx := TCPContext{app, conn, buf}
-	fmt.Println("Invoke TCP Proc", ctx.ProcID)
-	err = app.tcpProcs[ctx.ProcID].Proc(&tcpCtx)
-	if err != nil {
-		err := app.tcpProcs[ctx.ProcID].ErrorHandler(err, &tcpCtx)
-		if err != nil {
-			fmt.Println(err)
-			return
-		}
+	if app.Config.GracePeriod != 0 && app.Games.FindByPlayer(player.ID) != nil {
+		fmt.Println("hold the slot of player", player.ID, "for", app.Config.GracePeriod)
+		player.Detach()
+		return
 	}
+	app.Disconnect(player)
 }
 
 func (app *App) HandleUdpProc(conn *net.UDPConn) {
 	buf := make([]byte, 4096)
-	_, udpAddr, err := conn.ReadFromUDP(buf)
+	n, udpAddr, err := conn.ReadFromUDP(buf)
 	if err != nil {
 		fmt.Println("failed to read UDP msg because of", err)
 		return
 	}
-	ctx, err := rpc.ParseCall(buf[:5])
+	if n < rpc.CallHeaderSize {
+		fmt.Println("drop UDP msg from", udpAddr, "because the header is incomplete")
+		return
+	}
+	ctx, err := rpc.ParseCall(buf[:rpc.CallHeaderSize])
 	if err != nil {
 		fmt.Println(err)
 		return
 	}
-	if !(ctx.ProcID <= app.udpProcNum) {
+	if uint64(ctx.ContentLength) > uint64(n-rpc.CallHeaderSize) {
+		fmt.Println("drop UDP msg from", udpAddr, "because the content is truncated")
+		return
+	}
+	if !(ctx.ProcID < app.udpProcNum) {
 		return
 	}
 	var data []byte
 	if ctx.ContentLength != 0 {
-		data = buf[5 : 5+ctx.ContentLength]
+		data = buf[rpc.CallHeaderSize : rpc.CallHeaderSize+ctx.ContentLength]
 	} else {
 		data = nil
 	}
@@ -115,14 +150,15 @@ func (app *App) AddUDPProc(proc UDPProc) {
 }
 
 func (app *App) Start() {
-	host := flag.String("host", "localhost", "host")
-	procPort := flag.String("tcpproc-port", "23455", "procedure port")
-	gamePort := flag.String("game-port", "23456", "game port")
+	app.Config.ParseFlags()
 
-	flag.Parse()
+	app.Accounts = openAccountStore(app.Config.AccountsPath)
+	app.Maps = loadMaps(app.Config.MapsPath)
+	checkMaxFrameSize(app)
+	checkLobbyDefaults(app)
 
-	tcpServer := startTCPServer(host, procPort)
-	udpServer := startUDPServer(host, gamePort)
+	tcpServer := startTCPServer(&app.Config.Host, &app.Config.ProcPort)
+	udpServer := startUDPServer(&app.Config.Host, &app.Config.GamePort)
 
 	defer closeServer(tcpServer)
 	defer closeServer(udpServer)
@@ -132,6 +168,9 @@ func (app *App) Start() {
 			app.HandleUdpProc(udpServer)
 		}
 	}()
+	go app.Reliable.Run()
+	go app.watchPlayers()
+	go app.runMatchmaking()
 
 	for {
 		conn, err := tcpServer.AcceptTCP()
@@ -155,6 +194,48 @@ func closeServer(server server) {
 	}
 }
 
+func openAccountStore(path string) account.Store {
+	store, err := account.NewFileStore(path)
+	if err != nil {
+		fmt.Println("Can't load accounts: ", err)
+		os.Exit(1)
+	}
+	return store
+}
+
+// checkMaxFrameSize exit if the max frame size doesn't fit in the length field of a frame, because it would be truncated
+// to another limit.
+func checkMaxFrameSize(app *App) {
+	if app.Config.MaxFrameSize == 0 || uint64(app.Config.MaxFrameSize) > math.MaxUint32 {
+		fmt.Println("Max frame size must be between 1 and", uint64(math.MaxUint32))
+		os.Exit(1)
+	}
+}
+
+// checkLobbyDefaults exit if the default lobby settings are out of the limits, because every lobby would fail to be
+// created.
+func checkLobbyDefaults(app *App) {
+	defaults := app.LobbyDefaults()
+	err := defaults.Validate(app.LobbyLimits())
+	if err != nil {
+		fmt.Println("Default lobby settings are out of the limits:", err)
+		os.Exit(1)
+	}
+}
+
+func loadMaps(dir string) map[string]*game.Map {
+	maps, err := game.LoadMaps(dir)
+	if err != nil {
+		fmt.Println("Can't load maps: ", err)
+		os.Exit(1)
+	}
+	if _, ok := maps[game.DefaultMap]; !ok {
+		fmt.Println("Can't find the default map", game.DefaultMap, "in", dir)
+		os.Exit(1)
+	}
+	return maps
+}
+
 func startTCPServer(host *string, port *string) *net.TCPListener {
 	addr, err := net.ResolveTCPAddr("tcp", *host+":"+*port)
 	if err != nil {
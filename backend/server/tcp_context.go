@@ -1,11 +1,12 @@
 package server
 
 import (
-	"net"
+	"github.com/ppodds/hide-and-seek/server/rpc"
 )
 
 type TCPContext struct {
-	App  *App
-	Conn *net.TCPConn
-	Data []byte
+	App       *App
+	Session   *rpc.Session
+	RequestID uint32
+	Data      []byte
 }
@@ -0,0 +1,46 @@
+package tcpproc
+
+import (
+	"fmt"
+	"github.com/ppodds/hide-and-seek/protos"
+	"github.com/ppodds/hide-and-seek/server"
+)
+
+type BanPlayer struct {
+}
+
+func (banPlayer *BanPlayer) Proc(ctx *server.TCPContext) error {
+	req := new(protos.BanPlayerRequest)
+	err := unmarshalData(ctx, req)
+	if err != nil {
+		return err
+	}
+	l, target, err := moderationTarget(ctx, req.Player, req.Lobby, req.Target)
+	if err != nil {
+		return err
+	}
+	l.Ban(target)
+	// the target may have left already, then it's only banned
+	if l.HasPlayer(target.ID) {
+		err = ctx.App.Kick(l, target)
+		if err != nil {
+			return err
+		}
+	}
+	res := &protos.BanPlayerResponse{Success: true}
+	err = sendRes(ctx, res)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (banPlayer *BanPlayer) ErrorHandler(procErr error, ctx *server.TCPContext) error {
+	fmt.Println(procErr)
+	res := &protos.BanPlayerResponse{Success: false}
+	err := sendRes(ctx, res)
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/ppodds/hide-and-seek/protos"
 	"github.com/ppodds/hide-and-seek/server"
+	lobby2 "github.com/ppodds/hide-and-seek/server/lobby"
 )
 
 type CreateLobby struct {
@@ -16,9 +17,9 @@ func (createLobby *CreateLobby) Proc(ctx *server.TCPContext) error {
 	if err != nil {
 		return err
 	}
-	lead, ok := ctx.App.Players.Players()[req.Lead.Id]
-	if !ok {
-		return errors.New("invalid player id")
+	lead, err := authPlayer(ctx, req.Lead)
+	if err != nil {
+		return err
 	}
 	// check if the player already create a lobby
 	check := false
@@ -31,7 +32,19 @@ func (createLobby *CreateLobby) Proc(ctx *server.TCPContext) error {
 	if check {
 		return errors.New("player already created a lobby")
 	}
-	lobby := ctx.App.Lobbies.AddLobby(lead, 4)
+	settings, err := lobby2.NewSettings(req.Settings, ctx.App.LobbyDefaults(), ctx.App.LobbyLimits())
+	if err != nil {
+		return err
+	}
+	ctx.App.Membership.Lock()
+	lobby, err := ctx.App.Lobbies.AddLobby(lead, settings, req.Password)
+	if err == nil {
+		ctx.App.Matchmaker.Dequeue(lead.ID)
+	}
+	ctx.App.Membership.Unlock()
+	if err != nil {
+		return err
+	}
 	protoLobby, err := lobby.MarshalProtoBuf()
 	if err != nil {
 		return err
@@ -0,0 +1,24 @@
+package tcpproc
+
+import (
+	"fmt"
+	"github.com/ppodds/hide-and-seek/protos"
+	"github.com/ppodds/hide-and-seek/server"
+)
+
+// Heartbeat keep the session alive. The session itself identify the player, so no request data is needed.
+type Heartbeat struct {
+}
+
+func (heartbeat *Heartbeat) Proc(ctx *server.TCPContext) error {
+	err := sendRes(ctx, &protos.Heartbeat{})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (heartbeat *Heartbeat) ErrorHandler(procErr error, ctx *server.TCPContext) error {
+	fmt.Println(procErr)
+	return nil
+}
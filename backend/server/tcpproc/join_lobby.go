@@ -5,8 +5,8 @@ import (
 	"fmt"
 	"github.com/ppodds/hide-and-seek/protos"
 	"github.com/ppodds/hide-and-seek/server"
-	"github.com/ppodds/hide-and-seek/server/rpc"
-	"google.golang.org/protobuf/proto"
+	lobby2 "github.com/ppodds/hide-and-seek/server/lobby"
+	player2 "github.com/ppodds/hide-and-seek/server/player"
 )
 
 type JoinLobby struct {
@@ -18,13 +18,32 @@ func (joinLobby *JoinLobby) Proc(ctx *server.TCPContext) error {
 	if err != nil {
 		return err
 	}
-	player, ok := ctx.App.Players.Players()[req.Player.Id]
-	if !ok {
-		return errors.New("invalid player id")
+	player, err := authPlayer(ctx, req.Player)
+	if err != nil {
+		return err
+	}
+	var lobby *lobby2.Lobby
+	if req.InviteCode != "" {
+		var ok bool
+		lobby, ok = ctx.App.Lobbies.FindByCode(req.InviteCode)
+		if !ok {
+			return errors.New("invalid invite code")
+		}
+	} else {
+		var ok bool
+		lobby, ok = ctx.App.Lobbies.Lobbies()[req.GetLobby().GetId()]
+		if !ok {
+			return errors.New("invalid lobby id")
+		}
+		if lobby.Settings().Private {
+			return errors.New("private lobby can only be joined with the invite code")
+		}
+	}
+	if !lobby.CheckPassword(req.Password) {
+		return errors.New("wrong lobby password")
 	}
-	lobby, ok := ctx.App.Lobbies.Lobbies()[req.Lobby.Id]
-	if !ok {
-		return errors.New("invalid lobby id")
+	if lobby.IsBanned(player) {
+		return errors.New("player is banned from the lobby")
 	}
 	// check if player is already in the lobby
 	for _, p := range lobby.Players() {
@@ -32,7 +51,12 @@ func (joinLobby *JoinLobby) Proc(ctx *server.TCPContext) error {
 			return errors.New("player is already in the lobby")
 		}
 	}
+	ctx.App.Membership.Lock()
 	lobby, err = lobby.AddPlayer(player)
+	if err == nil {
+		ctx.App.Matchmaker.Dequeue(player.ID)
+	}
+	ctx.App.Membership.Unlock()
 	if err != nil {
 		return err
 	}
@@ -41,28 +65,27 @@ func (joinLobby *JoinLobby) Proc(ctx *server.TCPContext) error {
 		return err2
 	}
 	// send success to client
-	t2 := &protos.JoinLobbyResponse{Success: true, Lobby: protoLobby}
+	chat, err := lobby.MarshalChat()
+	if err != nil {
+		return err
+	}
+	t2 := &protos.JoinLobbyResponse{Success: true, Lobby: protoLobby, Chat: chat}
 	err = sendRes(ctx, t2)
 	if err != nil {
 		return err
 	}
 	// broadcast to lobby
 	t := &protos.LobbyBroadcast{Event: protos.LobbyEvent_JOIN, Lobby: protoLobby}
-	data, err := proto.Marshal(t)
-	if err != nil {
-		fmt.Println(err)
-		return nil
-	}
+	others := make([]*player2.Player, 0)
 	for _, p := range lobby.Players() {
-		if p.ID == player.ID {
-			continue
-		}
-		err = rpc.SendUDPRes(p.UDPConn(), p.UDPAddr(), data)
-		if err != nil {
-			fmt.Println("skip broadcast to", p.UDPAddr(), "because", err)
-			continue
+		if p.ID != player.ID {
+			others = append(others, p)
 		}
 	}
+	err = server.Push(others, t)
+	if err != nil {
+		fmt.Println(err)
+	}
 	return nil
 }
 
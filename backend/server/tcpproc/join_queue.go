@@ -0,0 +1,51 @@
+package tcpproc
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ppodds/hide-and-seek/protos"
+	"github.com/ppodds/hide-and-seek/server"
+	"github.com/ppodds/hide-and-seek/server/lobby"
+)
+
+type JoinQueue struct {
+}
+
+func (joinQueue *JoinQueue) Proc(ctx *server.TCPContext) error {
+	req := new(protos.JoinQueueRequest)
+	err := unmarshalData(ctx, req)
+	if err != nil {
+		return err
+	}
+	player, err := authPlayer(ctx, req.Player)
+	if err != nil {
+		return err
+	}
+	if ctx.App.Lobbies.FindByPlayer(player.ID) != nil {
+		return errors.New("player is already in a lobby")
+	}
+	settings, err := lobby.NewSettings(&protos.LobbySettings{MaxPlayers: req.PartySize, Map: req.Map}, ctx.App.LobbyDefaults(), ctx.App.LobbyLimits())
+	if err != nil {
+		return err
+	}
+	err = ctx.App.Matchmaker.Enqueue(player, settings)
+	if err != nil {
+		return err
+	}
+	res := &protos.JoinQueueResponse{Success: true}
+	err = sendRes(ctx, res)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (joinQueue *JoinQueue) ErrorHandler(procErr error, ctx *server.TCPContext) error {
+	fmt.Println(procErr)
+	res := &protos.JoinQueueResponse{Success: false}
+	err := sendRes(ctx, res)
+	if err != nil {
+		return err
+	}
+	return nil
+}
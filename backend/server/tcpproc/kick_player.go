@@ -0,0 +1,78 @@
+package tcpproc
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ppodds/hide-and-seek/protos"
+	"github.com/ppodds/hide-and-seek/server"
+	"github.com/ppodds/hide-and-seek/server/lobby"
+	"github.com/ppodds/hide-and-seek/server/player"
+)
+
+type KickPlayer struct {
+}
+
+func (kickPlayer *KickPlayer) Proc(ctx *server.TCPContext) error {
+	req := new(protos.KickPlayerRequest)
+	err := unmarshalData(ctx, req)
+	if err != nil {
+		return err
+	}
+	l, target, err := moderationTarget(ctx, req.Player, req.Lobby, req.Target)
+	if err != nil {
+		return err
+	}
+	if !l.HasPlayer(target.ID) {
+		return errors.New("target is not in the lobby")
+	}
+	err = ctx.App.Kick(l, target)
+	if err != nil {
+		return err
+	}
+	res := &protos.KickPlayerResponse{Success: true}
+	err = sendRes(ctx, res)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (kickPlayer *KickPlayer) ErrorHandler(procErr error, ctx *server.TCPContext) error {
+	fmt.Println(procErr)
+	res := &protos.KickPlayerResponse{Success: false}
+	err := sendRes(ctx, res)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// moderationTarget return the lobby and the target of a moderation request. The request must be made by the lead,
+// the target can't be the lead, and players can't be removed during a game.
+func moderationTarget(ctx *server.TCPContext, claim *protos.Player, lobbyClaim *protos.Lobby, targetClaim *protos.Player) (*lobby.Lobby, *player.Player, error) {
+	p, err := authPlayer(ctx, claim)
+	if err != nil {
+		return nil, nil, err
+	}
+	l, ok := ctx.App.Lobbies.Lobbies()[lobbyClaim.GetId()]
+	if !ok {
+		return nil, nil, errors.New("invalid lobby id")
+	}
+	if l.Lead().ID != p.ID {
+		return nil, nil, errors.New("not the lobby lead")
+	}
+	if l.InGame() {
+		return nil, nil, errors.New("game is already started")
+	}
+	if targetClaim == nil {
+		return nil, nil, errors.New("client doesn't provide target")
+	}
+	target, ok := ctx.App.Players.Players()[targetClaim.Id]
+	if !ok {
+		return nil, nil, errors.New("invalid target id")
+	}
+	if target.ID == p.ID {
+		return nil, nil, errors.New("lead can't remove itself")
+	}
+	return l, target, nil
+}
@@ -0,0 +1,42 @@
+package tcpproc
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ppodds/hide-and-seek/protos"
+	"github.com/ppodds/hide-and-seek/server"
+)
+
+type LeaveQueue struct {
+}
+
+func (leaveQueue *LeaveQueue) Proc(ctx *server.TCPContext) error {
+	req := new(protos.LeaveQueueRequest)
+	err := unmarshalData(ctx, req)
+	if err != nil {
+		return err
+	}
+	player, err := authPlayer(ctx, req.Player)
+	if err != nil {
+		return err
+	}
+	if !ctx.App.Matchmaker.Dequeue(player.ID) {
+		return errors.New("player is not in the queue")
+	}
+	res := &protos.LeaveQueueResponse{Success: true}
+	err = sendRes(ctx, res)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (leaveQueue *LeaveQueue) ErrorHandler(procErr error, ctx *server.TCPContext) error {
+	fmt.Println(procErr)
+	res := &protos.LeaveQueueResponse{Success: false}
+	err := sendRes(ctx, res)
+	if err != nil {
+		return err
+	}
+	return nil
+}
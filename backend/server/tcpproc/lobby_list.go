@@ -2,21 +2,32 @@ package tcpproc
 
 import (
 	"fmt"
+	"github.com/ppodds/hide-and-seek/protos"
 	"github.com/ppodds/hide-and-seek/server"
 )
 
 type LobbyList struct{}
 
 func (lobbyList *LobbyList) Proc(ctx *server.TCPContext) error {
-	data, err := ctx.App.Lobbies.MarshalProtoBuf()
+	req := new(protos.LobbyListRequest)
+	// an empty request list the first page of every lobby
+	if ctx.Data != nil {
+		err := unmarshalData(ctx, req)
+		if err != nil {
+			return err
+		}
+	}
+	res, err := ctx.App.Lobbies.List(req)
 	if err != nil {
 		return err
 	}
-	err = sendRes(ctx, data)
+	err = sendRes(ctx, res)
 	return err
 }
 
 func (lobbyList *LobbyList) ErrorHandler(procErr error, ctx *server.TCPContext) error {
 	fmt.Println(procErr)
-	return nil
+	res := &protos.LobbyListResponse{Success: false}
+	err := sendRes(ctx, res)
+	return err
 }
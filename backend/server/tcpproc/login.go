@@ -1,24 +1,91 @@
 package tcpproc
 
 import (
+	"errors"
 	"fmt"
 	"github.com/ppodds/hide-and-seek/protos"
 	"github.com/ppodds/hide-and-seek/server"
+	"github.com/ppodds/hide-and-seek/server/account"
 )
 
 type Login struct {
 }
 
 func (login *Login) Proc(ctx *server.TCPContext) error {
-	player := ctx.App.Players.AddPlayer(ctx.Conn)
-	err := sendRes(ctx, &protos.Player{Id: player.ID})
+	req := new(protos.LoginRequest)
+	err := unmarshalData(ctx, req)
+	if err != nil {
+		return err
+	}
+	// a session can only log in as one player
+	_, ok := ctx.App.Players.FindBySession(ctx.Session)
+	if ok {
+		return errors.New("session is already logged in")
+	}
+	username, err := account.NormalizeUsername(req.Username)
+	if err != nil {
+		return err
+	}
+	acc, err := loginAccount(ctx.App.Accounts, username, req.GetPassword())
+	if err != nil {
+		return err
+	}
+	player, err := ctx.App.Players.AddPlayer(ctx.Session, acc.Username)
+	if err != nil {
+		return err
+	}
+	res := &protos.LoginResponse{
+		Success: true,
+		Player:  &protos.Player{Id: player.ID, Token: player.Token(), Name: player.Name()},
+		Stats: &protos.PlayerStats{
+			Games:   acc.Stats.Games,
+			Wins:    acc.Stats.Wins,
+			Losses:  acc.Stats.Losses,
+			Catches: acc.Stats.Catches,
+		},
+	}
+	err = sendRes(ctx, res)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+// loginAccount return the account of username, which is created on the first login. If another session creates the
+// account at the same time, the password is checked against that account.
+func loginAccount(store account.Store, username string, password string) (*account.Account, error) {
+	acc, err := store.Get(username)
+	if errors.Is(err, account.ErrNotFound) {
+		acc = account.NewAccount(username)
+		if password != "" {
+			err = acc.SetPassword(password)
+			if err != nil {
+				return nil, err
+			}
+		}
+		err = store.Create(acc)
+		if err == nil {
+			return acc, nil
+		}
+		if !errors.Is(err, account.ErrExists) {
+			return nil, err
+		}
+		acc, err = store.Get(username)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !acc.CheckPassword(password) {
+		return nil, errors.New("wrong password")
+	}
+	return acc, nil
+}
+
 func (login *Login) ErrorHandler(procErr error, ctx *server.TCPContext) error {
 	fmt.Println(procErr)
+	err := sendRes(ctx, &protos.LoginResponse{Success: false})
+	if err != nil {
+		return err
+	}
 	return nil
 }
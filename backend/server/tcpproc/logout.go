@@ -15,7 +15,11 @@ func (logout *Logout) Proc(ctx *server.TCPContext) error {
 	if err != nil {
 		return err
 	}
-	ctx.App.Players.RmPlayer(req.Player)
+	player, err := authPlayer(ctx, req.Player)
+	if err != nil {
+		return err
+	}
+	ctx.App.Disconnect(player)
 	return nil
 }
 
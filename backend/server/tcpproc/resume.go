@@ -0,0 +1,76 @@
+package tcpproc
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ppodds/hide-and-seek/protos"
+	"github.com/ppodds/hide-and-seek/server"
+)
+
+// Resume bind the session to a player logged in before, so a client can get back into its lobby and game after a
+// network drop. The client should send ConnectGame again to rebind its udp address.
+type Resume struct {
+}
+
+func (resume *Resume) Proc(ctx *server.TCPContext) error {
+	req := new(protos.ResumeRequest)
+	err := unmarshalData(ctx, req)
+	if err != nil {
+		return err
+	}
+	if req.Player == nil {
+		return errors.New("client doesn't provide player")
+	}
+	player, ok := ctx.App.Players.Players()[req.Player.Id]
+	if !ok {
+		return errors.New("invalid player id")
+	}
+	if !player.Authenticate(req.Player.Token) {
+		return errors.New("invalid session token")
+	}
+	if p, ok := ctx.App.Players.FindBySession(ctx.Session); ok && p.ID != player.ID {
+		return errors.New("session is already logged in")
+	}
+	old := player.Attach(ctx.Session)
+	if old != nil && old != ctx.Session {
+		// the old connection is half open, drop it
+		err = old.Close()
+		if err != nil {
+			fmt.Println("Error closing session:", err)
+		}
+	}
+	player.TouchUDP()
+	res := &protos.ResumeResponse{
+		Success: true,
+		Player:  &protos.Player{Id: player.ID, Token: player.Token(), Name: player.Name()},
+	}
+	for _, lobby := range ctx.App.Lobbies.Lobbies() {
+		if lobby.HasPlayer(player.ID) {
+			res.Lobby, err = lobby.MarshalProtoBuf()
+			if err != nil {
+				return err
+			}
+			break
+		}
+	}
+	if game := ctx.App.Games.FindByPlayer(player.ID); game != nil {
+		res.InitGame, err = game.MarshalProtoBuf()
+		if err != nil {
+			return err
+		}
+	}
+	err = sendRes(ctx, res)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (resume *Resume) ErrorHandler(procErr error, ctx *server.TCPContext) error {
+	fmt.Println(procErr)
+	err := sendRes(ctx, &protos.ResumeResponse{Success: false})
+	if err != nil {
+		return err
+	}
+	return nil
+}
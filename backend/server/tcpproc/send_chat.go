@@ -0,0 +1,82 @@
+package tcpproc
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ppodds/hide-and-seek/protos"
+	"github.com/ppodds/hide-and-seek/server"
+	lobby2 "github.com/ppodds/hide-and-seek/server/lobby"
+	player2 "github.com/ppodds/hide-and-seek/server/player"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+type SendChat struct {
+}
+
+func (sendChat *SendChat) Proc(ctx *server.TCPContext) error {
+	req := new(protos.SendChatRequest)
+	err := unmarshalData(ctx, req)
+	if err != nil {
+		return err
+	}
+	player, err := authPlayer(ctx, req.Player)
+	if err != nil {
+		return err
+	}
+	lobby, ok := ctx.App.Lobbies.Lobbies()[req.GetLobby().GetId()]
+	if !ok {
+		return errors.New("invalid lobby id")
+	}
+	if !lobby.HasPlayer(player.ID) {
+		return errors.New("player is not in the lobby")
+	}
+	text := strings.TrimSpace(req.Text)
+	if text == "" || !utf8.ValidString(text) {
+		return errors.New("invalid chat message")
+	}
+	if uint(utf8.RuneCountInString(text)) > ctx.App.Config.MaxChatLength {
+		return errors.New("chat message is too long")
+	}
+	if !player.AllowChat(ctx.App.Config.ChatInterval, ctx.App.Config.ChatBurst) {
+		return errors.New("player sends chat messages too fast")
+	}
+	msg := &lobby2.ChatMessage{Sender: player, Text: text, SentAt: time.Now()}
+	recipients := lobby.Players()
+	// during a game, messages only go to the team of the sender, so they are not kept for players who join later
+	if g := ctx.App.Games.FindByPlayer(player.ID); g != nil && lobby.InGame() {
+		msg.Team = true
+		recipients = make([]*player2.Player, 0)
+		if sender, ok := g.Players()[player.ID]; ok {
+			for _, p := range g.Players() {
+				if p.Character().Type() == sender.Character().Type() {
+					recipients = append(recipients, p.Player())
+				}
+			}
+		}
+	} else {
+		lobby.AddChat(msg, ctx.App.Config.ChatHistory)
+	}
+	res := &protos.SendChatResponse{Success: true}
+	err = sendRes(ctx, res)
+	if err != nil {
+		return err
+	}
+	chat, err := msg.MarshalProtoBuf()
+	if err != nil {
+		return err
+	}
+	broadcast := &protos.LobbyBroadcast{Event: protos.LobbyEvent_CHAT, Chat: chat}
+	return server.Push(recipients, broadcast)
+}
+
+func (sendChat *SendChat) ErrorHandler(procErr error, ctx *server.TCPContext) error {
+	fmt.Println(procErr)
+	res := &protos.SendChatResponse{Success: false}
+	err := sendRes(ctx, res)
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package tcpproc
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ppodds/hide-and-seek/protos"
+	"github.com/ppodds/hide-and-seek/server"
+)
+
+type SetReady struct {
+}
+
+func (setReady *SetReady) Proc(ctx *server.TCPContext) error {
+	req := new(protos.SetReadyRequest)
+	err := unmarshalData(ctx, req)
+	if err != nil {
+		return err
+	}
+	player, err := authPlayer(ctx, req.Player)
+	if err != nil {
+		return err
+	}
+	lobby, ok := ctx.App.Lobbies.Lobbies()[req.GetLobby().GetId()]
+	if !ok {
+		return errors.New("invalid lobby id")
+	}
+	if lobby.InGame() {
+		return errors.New("game is already started")
+	}
+	err = lobby.SetReady(player, req.Ready)
+	if err != nil {
+		return err
+	}
+	res := &protos.SetReadyResponse{Success: true}
+	err = sendRes(ctx, res)
+	if err != nil {
+		return err
+	}
+	lobbyProto, err := lobby.MarshalProtoBuf()
+	if err != nil {
+		return err
+	}
+	broadcast := &protos.LobbyBroadcast{Event: protos.LobbyEvent_READY_CHANGED, Lobby: lobbyProto}
+	return server.Push(lobby.Players(), broadcast)
+}
+
+func (setReady *SetReady) ErrorHandler(procErr error, ctx *server.TCPContext) error {
+	fmt.Println(procErr)
+	res := &protos.SetReadyResponse{Success: false}
+	err := sendRes(ctx, res)
+	if err != nil {
+		return err
+	}
+	return nil
+}
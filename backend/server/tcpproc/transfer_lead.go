@@ -0,0 +1,62 @@
+package tcpproc
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ppodds/hide-and-seek/protos"
+	"github.com/ppodds/hide-and-seek/server"
+)
+
+type TransferLead struct {
+}
+
+func (transferLead *TransferLead) Proc(ctx *server.TCPContext) error {
+	req := new(protos.TransferLeadRequest)
+	err := unmarshalData(ctx, req)
+	if err != nil {
+		return err
+	}
+	player, err := authPlayer(ctx, req.Player)
+	if err != nil {
+		return err
+	}
+	lobby, ok := ctx.App.Lobbies.Lobbies()[req.GetLobby().GetId()]
+	if !ok {
+		return errors.New("invalid lobby id")
+	}
+	if lobby.Lead().ID != player.ID {
+		return errors.New("not the lobby lead")
+	}
+	if req.Target == nil {
+		return errors.New("client doesn't provide target")
+	}
+	target, ok := ctx.App.Players.Players()[req.Target.Id]
+	if !ok {
+		return errors.New("invalid target id")
+	}
+	err = lobby.SetLead(target)
+	if err != nil {
+		return err
+	}
+	res := &protos.TransferLeadResponse{Success: true}
+	err = sendRes(ctx, res)
+	if err != nil {
+		return err
+	}
+	lobbyProto, err := lobby.MarshalProtoBuf()
+	if err != nil {
+		return err
+	}
+	broadcast := &protos.LobbyBroadcast{Event: protos.LobbyEvent_LEAD_CHANGED, Lobby: lobbyProto}
+	return server.Push(lobby.Players(), broadcast)
+}
+
+func (transferLead *TransferLead) ErrorHandler(procErr error, ctx *server.TCPContext) error {
+	fmt.Println(procErr)
+	res := &protos.TransferLeadResponse{Success: false}
+	err := sendRes(ctx, res)
+	if err != nil {
+		return err
+	}
+	return nil
+}
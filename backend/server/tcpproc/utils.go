@@ -2,8 +2,9 @@ package tcpproc
 
 import (
 	"errors"
+	"github.com/ppodds/hide-and-seek/protos"
 	"github.com/ppodds/hide-and-seek/server"
-	"github.com/ppodds/hide-and-seek/server/rpc"
+	"github.com/ppodds/hide-and-seek/server/player"
 	"google.golang.org/protobuf/proto"
 )
 
@@ -15,11 +16,30 @@ func unmarshalData(ctx *server.TCPContext, msg proto.Message) error {
 	return err
 }
 
+// authPlayer return the player claimed by the request. The token must match and the request must come from the session
+// the player logged in with.
+func authPlayer(ctx *server.TCPContext, claim *protos.Player) (*player.Player, error) {
+	if claim == nil {
+		return nil, errors.New("client doesn't provide player")
+	}
+	p, ok := ctx.App.Players.Players()[claim.Id]
+	if !ok {
+		return nil, errors.New("invalid player id")
+	}
+	if !p.Authenticate(claim.Token) {
+		return nil, errors.New("invalid session token")
+	}
+	if p.Session() != ctx.Session {
+		return nil, errors.New("player is logged in with another session")
+	}
+	return p, nil
+}
+
 func sendRes(ctx *server.TCPContext, msg proto.Message) error {
 	buf, err := proto.Marshal(msg)
 	if err != nil {
 		return err
 	}
-	err = rpc.SendTCPRes(ctx.Conn, buf)
+	err = ctx.Session.WriteRes(ctx.RequestID, buf)
 	return err
 }
@@ -0,0 +1,30 @@
+package udpproc
+
+import (
+	"fmt"
+	"github.com/ppodds/hide-and-seek/protos"
+	"github.com/ppodds/hide-and-seek/server"
+)
+
+type AckReliable struct {
+}
+
+func (ackReliable *AckReliable) Proc(ctx *server.UDPContext) error {
+	req := new(protos.ReliableAck)
+	err := unmarshalData(ctx, req)
+	if err != nil {
+		return err
+	}
+	// only the player bound to the address can ack its datagrams
+	p, err := authPlayer(ctx, req.Player, false)
+	if err != nil {
+		return err
+	}
+	ctx.App.Reliable.Ack(p.UDPAddr(), req.Seq)
+	return nil
+}
+
+func (ackReliable *AckReliable) ErrorHandler(procErr error, ctx *server.UDPContext) error {
+	fmt.Println(procErr)
+	return nil
+}
@@ -1,7 +1,6 @@
 package udpproc
 
 import (
-	"errors"
 	"fmt"
 	"github.com/ppodds/hide-and-seek/protos"
 	"github.com/ppodds/hide-and-seek/server"
@@ -16,12 +15,11 @@ func (connectLobby *ConnectLobby) Proc(ctx *server.UDPContext) error {
 	if err != nil {
 		return err
 	}
-	player, ok := ctx.App.Players.Players()[req.Player.Id]
-	if !ok {
-		return errors.New("invalid player id")
+	player, err := authPlayer(ctx, req.Player, true)
+	if err != nil {
+		return err
 	}
-	player.SetUDPConn(ctx.Conn)
-	player.SetUDPAddr(ctx.Addr)
+	bindUDP(ctx, player)
 	err = sendRes(ctx, ctx.Addr, &protos.ConnectLobbyResponse{Success: true})
 	if err != nil {
 		return err
@@ -0,0 +1,29 @@
+package udpproc
+
+import (
+	"fmt"
+	"github.com/ppodds/hide-and-seek/protos"
+	"github.com/ppodds/hide-and-seek/server"
+)
+
+type Heartbeat struct {
+}
+
+func (heartbeat *Heartbeat) Proc(ctx *server.UDPContext) error {
+	req := new(protos.Heartbeat)
+	err := unmarshalData(ctx, req)
+	if err != nil {
+		return err
+	}
+	// authenticating the player record the heartbeat
+	_, err = authPlayer(ctx, req.Player, false)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (heartbeat *Heartbeat) ErrorHandler(procErr error, ctx *server.UDPContext) error {
+	fmt.Println(procErr)
+	return nil
+}
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"github.com/ppodds/hide-and-seek/protos"
 	"github.com/ppodds/hide-and-seek/server"
-	"time"
 )
 
 type UpdatePlayer struct {
@@ -21,56 +20,22 @@ func (updatePlayer *UpdatePlayer) Proc(ctx *server.UDPContext) error {
 	if !ok {
 		return errors.New("invalid game id")
 	}
-	player, ok := game.Players()[req.Player.Player.Id]
-	if !ok {
-		return errors.New("invalid player id")
+	if req.Player == nil {
+		return errors.New("client doesn't provide player")
 	}
-	player.SetCharacter(req.Player.Character)
-	// check if ghost win
-	liveCount := 0
-	for _, p := range game.Players() {
-		if !p.Character().Dead() {
-			liveCount++
-		}
+	p, err := authPlayer(ctx, req.Player.Player, false)
+	if err != nil {
+		return err
 	}
-	ghostWin := liveCount == 1
-	playerWin := time.Since(game.StartFrom()).Minutes() > 3
-	var data *protos.GameBroadcast
-	if ghostWin || playerWin {
-		var winner protos.CharacterType
-		if ghostWin {
-			winner = protos.CharacterType_GHOST
-		} else {
-			winner = protos.CharacterType_PLAYER
-		}
-		data = &protos.GameBroadcast{
-			Event:  protos.GameEvent_GAME_OVER,
-			Winner: &winner,
-		}
-		lobby, ok := ctx.App.Lobbies.Lobbies()[game.LobbyID()]
-		if !ok {
-			fmt.Println("failed to get game lobby")
-			return nil
-		}
-		lobby.SetInGame(false)
-		ctx.App.Games.RmGame(game.ID())
-	} else {
-		playerProto, err := player.MarshalProtoBuf()
-		if err != nil {
-			return err
-		}
-		data = &protos.GameBroadcast{
-			Event:  protos.GameEvent_UPDATE_PLAYER,
-			Player: playerProto,
-		}
+	player, ok := game.Players()[p.ID]
+	if !ok {
+		return errors.New("player is not in the game")
 	}
-	// broadcast
-	for _, p := range game.Players() {
-		err = sendRes(ctx, p.Player().UDPAddr(), data)
-		if err != nil {
-			fmt.Println("skip broadcast to player", p.Player().ID, "because", err)
-			continue
-		}
+	game.Ack(player.Player().ID, req.Ack)
+	// the input is applied on the next tick of the game
+	err = game.Input(player.Player().ID, req.Player.Character)
+	if err != nil {
+		return err
 	}
 	return nil
 }
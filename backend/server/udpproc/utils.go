@@ -2,7 +2,9 @@ package udpproc
 
 import (
 	"errors"
+	"github.com/ppodds/hide-and-seek/protos"
 	"github.com/ppodds/hide-and-seek/server"
+	"github.com/ppodds/hide-and-seek/server/player"
 	"github.com/ppodds/hide-and-seek/server/rpc"
 	"google.golang.org/protobuf/proto"
 	"net"
@@ -16,6 +18,38 @@ func unmarshalData(ctx *server.UDPContext, msg proto.Message) error {
 	return err
 }
 
+// authPlayer return the player claimed by the request if the token match. If handshake is false, the request must also
+// come from the udp address bound by the last handshake.
+func authPlayer(ctx *server.UDPContext, claim *protos.Player, handshake bool) (*player.Player, error) {
+	if claim == nil {
+		return nil, errors.New("client doesn't provide player")
+	}
+	p, ok := ctx.App.Players.Players()[claim.Id]
+	if !ok {
+		return nil, errors.New("invalid player id")
+	}
+	if !p.Authenticate(claim.Token) {
+		return nil, errors.New("invalid session token")
+	}
+	if !handshake && (p.UDPAddr() == nil || p.UDPAddr().String() != ctx.Addr.String()) {
+		return nil, errors.New("udp address changed without handshake")
+	}
+	p.TouchUDP()
+	return p, nil
+}
+
+// bindUDP bind the udp endpoint of the request to the player. The reliable channel of the endpoint restart from
+// sequence number 1.
+func bindUDP(ctx *server.UDPContext, p *player.Player) {
+	old := p.UDPAddr()
+	if old != nil && old.String() != ctx.Addr.String() {
+		ctx.App.Reliable.RmPeer(old)
+	}
+	ctx.App.Reliable.RmPeer(ctx.Addr)
+	p.SetUDPConn(ctx.Conn)
+	p.SetUDPAddr(ctx.Addr)
+}
+
 func sendRes(ctx *server.UDPContext, addr *net.UDPAddr, msg proto.Message) error {
 	buf, err := proto.Marshal(msg)
 	if err != nil {